@@ -1,23 +1,103 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"os"
 	"runtime"
 	"strings"
 	"time"
 
+	"linkedin-crawler/internal/api"
 	"linkedin-crawler/internal/config"
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/logging"
+	"linkedin-crawler/internal/metrics"
 	"linkedin-crawler/internal/orchestrator"
+	"linkedin-crawler/internal/orchestrator/coordinator"
+	"linkedin-crawler/internal/storage"
 	"linkedin-crawler/internal/utils"
 )
 
 func main() {
+	if len(os.Args) >= 3 && os.Args[1] == "cluster" && os.Args[2] == "status" {
+		runClusterStatus()
+		return
+	}
+	if len(os.Args) >= 3 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2])
+		return
+	}
+
+	reset := flag.Bool("reset", false, "drop and recreate the database schema instead of resuming from it")
+	metricsAddr := flag.String("metrics-addr", "", "listen address for the /metrics and /healthz endpoints (empty disables it)")
+	progressMode := flag.String("progress", "", "progress reporting mode: tty, json, prometheus, or auto (empty keeps the default)")
+	resume := flag.Bool("resume", false, "replay the checkpoint WAL on startup to recover in-flight emails from a hard crash")
+	coordinatorURL := flag.String("coordinator", "", "work-coordination backend: \"local\" (default, single-process) or a redis:// URL for distributed mode")
+	leaderElection := flag.String("leader-election", "", "leader-election backend: \"local\" (default, single-process, always leader) or a zk:// / etcd:// URL for distributed mode")
+	profileBackend := flag.String("profile-backend", "", "profile-fetching backend: \"linkedin\" (default) or \"rocketreach\" (not implemented yet)")
+	logLevel := flag.String("log-level", os.Getenv("LOG_LEVEL"), "log level: debug, info, warn, error (default info; $LOG_LEVEL)")
+	logFormat := flag.String("log-format", "", "log output format: text (default, for terminals) or json (for Loki/ELK)")
+	verbose := flag.Bool("v", false, "shorthand for -log-level=debug")
+	logSink := flag.String("log-sink", "", "structured run-log destination: file (default, JSON lines to crawler.log), stdout, or syslog")
+	logSyslogTag := flag.String("log-syslog-tag", "", "tag to log the run log under when -log-sink=syslog")
+	apiAddr := flag.String("api-addr", "", "listen address for the control-plane REST API (empty disables it)")
+	apiToken := flag.String("api-token", os.Getenv("API_TOKEN"), "shared bearer token required on every control-plane API request (empty leaves it unauthenticated; $API_TOKEN)")
+	workerID := flag.String("worker-id", "", "stable identity for this run's leases/heartbeats, for distributed deployments (empty generates a fresh UUID)")
+	flag.Parse()
+
+	if *verbose {
+		*logLevel = "debug"
+	}
+	if err := logging.Configure(*logFormat, *logLevel); err != nil {
+		log.Fatalf("❌ Cấu hình logging không hợp lệ: %v", err)
+	}
+
 	fmt.Println("🚀 LinkedIn Auto Crawler - SQLite Version")
 	fmt.Println(strings.Repeat("=", 60))
 
 	// Load configuration
 	cfg := config.DefaultConfig()
+	cfg.ResetDatabase = *reset
+	if *metricsAddr != "" {
+		cfg.MetricsAddr = *metricsAddr
+	}
+	if *progressMode != "" {
+		cfg.ProgressMode = *progressMode
+	}
+	cfg.Resume = *resume
+	if *coordinatorURL != "" {
+		cfg.Coordinator = *coordinatorURL
+	}
+	if *leaderElection != "" {
+		cfg.LeaderElection = *leaderElection
+	}
+	if *profileBackend != "" {
+		cfg.ProfileBackend = *profileBackend
+	}
+	if *logSink != "" {
+		cfg.LogSink = *logSink
+	}
+	if *logSyslogTag != "" {
+		cfg.LogSyslogTag = *logSyslogTag
+	}
+	if *apiAddr != "" {
+		cfg.APIAddr = *apiAddr
+	}
+	if *apiToken != "" {
+		cfg.APIToken = *apiToken
+	}
+	if *workerID != "" {
+		cfg.WorkerID = *workerID
+	}
+
+	if _, err := metrics.StartServer(cfg.MetricsAddr); err != nil {
+		log.Printf("⚠️ Không thể khởi động metrics server: %v", err)
+	} else if cfg.MetricsAddr != "" {
+		fmt.Printf("📈 Metrics: http://localhost%s/metrics\n", cfg.MetricsAddr)
+	}
 
 	// Create auto crawler with SQLite support
 	autoCrawler, err := orchestrator.New(cfg)
@@ -25,6 +105,16 @@ func main() {
 		log.Fatalf("❌ Lỗi khởi tạo auto crawler: %v", err)
 	}
 
+	if cfg.APIAddr != "" && cfg.APIToken == "" && !strings.HasPrefix(cfg.APIAddr, "127.0.0.1:") && !strings.HasPrefix(cfg.APIAddr, "localhost:") {
+		log.Printf("⚠️ Control-plane API đang mở ở %s mà không có -api-token: bất kỳ ai truy cập được cổng này đều có thể thêm/xoá account, token hoặc dừng crawler", cfg.APIAddr)
+	}
+
+	if _, err := api.NewServer(autoCrawler, cfg.APIToken).Start(cfg.APIAddr); err != nil {
+		log.Printf("⚠️ Không thể khởi động control-plane API: %v", err)
+	} else if cfg.APIAddr != "" {
+		fmt.Printf("🎛️  Control-plane API: http://localhost%s/v1\n", cfg.APIAddr)
+	}
+
 	// Start crawling
 	startTime := time.Now()
 	err = autoCrawler.Run()
@@ -46,3 +136,130 @@ func main() {
 
 	fmt.Println(strings.Repeat("=", 60))
 }
+
+// runClusterStatus implements `crawler cluster status`: it reports every
+// worker the configured coordinator currently knows about. Under the
+// default "local" coordinator this is only ever this one invocation's own
+// (empty) in-memory registry - local mode keeps no durable, cross-process
+// worker record, so there's nothing for a separate `cluster status`
+// process to read. A distributed backend (redis://...) would persist
+// heartbeats somewhere every worker and this command can both reach.
+func runClusterStatus() {
+	flag.CommandLine.Parse(os.Args[3:])
+
+	cfg := config.DefaultConfig()
+
+	dbPath := cfg.Storage.SQLitePath
+	if dbPath == "" {
+		dbPath = "crawler.db"
+	}
+	if err := storage.InitializeDatabase(dbPath); err != nil {
+		log.Fatalf("❌ Lỗi khởi tạo database: %v", err)
+	}
+	defer storage.CloseDatabase()
+
+	dbStorage := storage.GetDBStorage()
+	coord, err := coordinator.New(cfg.Coordinator, dbStorage.EmailRepo, dbStorage.AccountRepo)
+	if err != nil {
+		log.Fatalf("❌ Lỗi khởi tạo coordinator: %v", err)
+	}
+	defer coord.Close()
+
+	status, err := coord.Status(context.Background())
+	if err != nil {
+		log.Fatalf("❌ Lỗi lấy trạng thái cluster: %v", err)
+	}
+
+	if len(status.Workers) == 0 {
+		fmt.Println("Không có worker nào đang hoạt động.")
+		if cfg.Coordinator == "" || cfg.Coordinator == "local" {
+			fmt.Println("(coordinator=local không lưu worker registry giữa các tiến trình - chỉ thấy được trong cùng 1 process)")
+		}
+		return
+	}
+
+	fmt.Printf("%-38s %-12s %-10s %-10s %s\n", "WORKER", "PROCESSED", "SUCCESS", "FAILED", "LAST HEARTBEAT")
+	for _, w := range status.Workers {
+		fmt.Printf("%-38s %-12d %-10d %-10d %s\n",
+			w.WorkerID, w.Processed, w.Success, w.Failed, w.LastHeartbeat.Format(time.RFC3339))
+	}
+}
+
+// runMigrate implements `crawler migrate status|up|down`, opening the
+// database directly instead of going through orchestrator.New so schema
+// maintenance doesn't require standing up the whole crawler.
+func runMigrate(subcommand string) {
+	flag.CommandLine.Parse(os.Args[3:])
+
+	cfg := config.DefaultConfig()
+	dbPath := cfg.Storage.SQLitePath
+	if dbPath == "" {
+		dbPath = "crawler.db"
+	}
+
+	switch subcommand {
+	case "status":
+		runMigrateStatus(dbPath)
+	case "up":
+		runMigrateUp(dbPath)
+	case "down":
+		runMigrateDown(dbPath)
+	default:
+		log.Fatalf("❌ Lệnh migrate không xác định: %q (dùng status, up, hoặc down)", subcommand)
+	}
+}
+
+// runMigrateStatus prints every known migration and whether it has been
+// applied yet. Unlike runMigrateUp, this must succeed even against a schema
+// newer than the binary supports, so it opens the connection directly
+// instead of through database.New (whose Migrate call would refuse).
+func runMigrateStatus(dbPath string) {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		log.Fatalf("❌ Lỗi kết nối database: %v", err)
+	}
+	defer db.Close()
+
+	statuses, err := db.Status()
+	if err != nil {
+		log.Fatalf("❌ Lỗi đọc trạng thái migration: %v", err)
+	}
+
+	fmt.Printf("%-10s %-40s %s\n", "VERSION", "NAME", "APPLIED")
+	for _, s := range statuses {
+		applied := "no"
+		if s.Applied {
+			applied = "yes"
+		}
+		fmt.Printf("%-10d %-40s %s\n", s.Version, s.Name, applied)
+	}
+}
+
+// runMigrateUp applies every pending migration. database.New already does
+// this on every normal startup, so this subcommand exists for operators who
+// want schema changes to land ahead of a deploy instead of at crawl time.
+func runMigrateUp(dbPath string) {
+	db, err := database.New(dbPath)
+	if err != nil {
+		log.Fatalf("❌ Lỗi chạy migration: %v", err)
+	}
+	defer db.Close()
+	fmt.Println("✅ Database đã được cập nhật lên schema mới nhất.")
+}
+
+// runMigrateDown rolls back the single most recently applied migration.
+// Unlike --reset (database.NewWithReset), it doesn't touch any other
+// migration's tables, so it's safe to use for a quick undo of the last
+// deploy's schema change.
+func runMigrateDown(dbPath string) {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		log.Fatalf("❌ Lỗi kết nối database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.StepDown(); err != nil {
+		log.Fatalf("❌ Lỗi rollback migration: %v", err)
+	}
+	fmt.Println("✅ Đã rollback migration gần nhất.")
+}
@@ -0,0 +1,10 @@
+package api
+
+import "embed"
+
+// dashboardFS holds the static single-page dashboard served at "/": it
+// polls /v1/stats for live progress, so it works against any control-plane
+// API instance without a build step or external asset pipeline.
+//
+//go:embed index.html
+var dashboardFS embed.FS
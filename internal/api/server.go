@@ -0,0 +1,418 @@
+// Package api is the control-plane surface for driving and monitoring a
+// running crawler remotely: see crawler.proto for the contract this is
+// meant to implement. There's no protoc/grpc-go/grpc-gateway available in
+// this build (no network access, nothing vendored), so Server exposes the
+// same methods as a stdlib net/http JSON REST service instead - the
+// repo's established substitute for a client library that can't be
+// vendored (see internal/output's unsupported sinks, internal/metrics'
+// hand-rolled Prometheus exposition).
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/logging"
+	"linkedin-crawler/internal/models"
+	"linkedin-crawler/internal/orchestrator"
+)
+
+// Server wires the control-plane HTTP handlers to a running AutoCrawler's
+// dbStorage, batchProcessor, and retryHandler (via its exported accessors).
+type Server struct {
+	ac    *orchestrator.AutoCrawler
+	token string
+}
+
+// NewServer returns a Server driving ac. When token is non-empty, every
+// request must carry it as "Authorization: Bearer <token>" - the API can
+// add accounts, revoke tokens, and stop the crawler, so an empty token
+// should only be used for loopback-only deployments.
+func NewServer(ac *orchestrator.AutoCrawler, token string) *Server {
+	return &Server{ac: ac, token: token}
+}
+
+// Handler returns the http.Handler serving every control-plane endpoint.
+// Every /v1/* route requires bearer-token auth when s.token is set; the
+// embedded dashboard at "/" is exempt so the page itself can load and
+// prompt for the token before it needs one (see index.html's
+// ensureApiToken), rather than 401ing before the user ever sees it.
+func (s *Server) Handler() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("/v1/emails", s.handleEmails)
+	api.HandleFunc("/v1/emails/status", s.handleEmailStatus)
+	api.HandleFunc("/v1/accounts", s.handleAddAccount)
+	api.HandleFunc("/v1/tokens/revoke", s.handleRevokeToken)
+	api.HandleFunc("/v1/processing/pause", s.handlePause)
+	api.HandleFunc("/v1/processing/resume", s.handleResume)
+	api.HandleFunc("/v1/stats", s.handleStats)
+	api.HandleFunc("/v1/results/stream", s.handleStreamResults)
+	api.HandleFunc("/v1/tokens/reload", s.handleTokensReload)
+	api.HandleFunc("/v1/control/stop", s.handleControlStop)
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1/", s.authMiddleware(api))
+	mux.Handle("/", http.FileServer(http.FS(dashboardFS)))
+	return mux
+}
+
+// authMiddleware rejects any request missing a valid "Authorization: Bearer
+// <s.token>" header with 401, using a constant-time comparison so the
+// check doesn't leak the token's length/prefix through response timing. A
+// zero-value s.token disables the check entirely (local/loopback-only use).
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	if s.token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "Bearer "
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, prefix) ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, prefix)), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Start runs the control-plane API on addr in the background. An empty
+// addr disables it (returns nil, nil), matching metrics.StartServer.
+func (s *Server) Start(addr string) (*http.Server, error) {
+	if addr == "" {
+		return nil, nil
+	}
+
+	server := &http.Server{Addr: addr, Handler: s.Handler()}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return nil, err
+		}
+	default:
+	}
+
+	return server, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v != nil {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func recordToProto(r database.EmailRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                  r.ID,
+		"email":               r.Email,
+		"status":              string(r.Status),
+		"profile_user":        r.ProfileUser,
+		"profile_url":         r.ProfileURL,
+		"profile_location":    r.ProfileLocation,
+		"profile_connections": r.ProfileConnections,
+		"retry_count":         r.RetryCount,
+		"last_error":          r.LastError,
+	}
+}
+
+// handleEmails implements SubmitEmails (POST) and is the list-all fallback
+// GetEmailBatch falls back to when called with no query params (GET).
+func (s *Server) handleEmails(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.submitEmails(w, r)
+	case http.MethodGet:
+		s.getEmailBatch(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// submitEmails implements SubmitEmails: the gRPC contract streams emails
+// one at a time, which the REST gateway equivalent is a single request
+// carrying the whole batch as a JSON array.
+func (s *Server) submitEmails(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Emails []string `json:"emails"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.ac.GetDBStorage().EmailRepo.ImportEmails(req.Emails); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"accepted": len(req.Emails)})
+}
+
+// handleEmailStatus implements GetEmailStatus.
+func (s *Server) handleEmailStatus(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing email query param"))
+		return
+	}
+
+	record, err := s.ac.GetDBStorage().EmailRepo.GetEmail(email)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, recordToProto(record))
+}
+
+// getEmailBatch implements GetEmailBatch, using the last page's highest id
+// as page_token - the same cursor-over-id pagination EmailRepository.
+// GetEmailPage exposes.
+func (s *Server) getEmailBatch(w http.ResponseWriter, r *http.Request) {
+	status := database.EmailStatus(r.URL.Query().Get("status"))
+
+	pageSize := 100
+	if ps := r.URL.Query().Get("page_size"); ps != "" {
+		n, err := strconv.Atoi(ps)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid page_size %q", ps))
+			return
+		}
+		pageSize = n
+	}
+
+	var afterID int64
+	if pt := r.URL.Query().Get("page_token"); pt != "" {
+		id, err := strconv.ParseInt(pt, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid page_token %q", pt))
+			return
+		}
+		afterID = id
+	}
+
+	records, err := s.ac.GetDBStorage().EmailRepo.GetEmailPage(status, afterID, pageSize)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	emails := make([]map[string]interface{}, len(records))
+	nextPageToken := ""
+	for i, rec := range records {
+		emails[i] = recordToProto(rec)
+	}
+	if len(records) == pageSize {
+		nextPageToken = strconv.FormatInt(records[len(records)-1].ID, 10)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"emails":          emails,
+		"next_page_token": nextPageToken,
+	})
+}
+
+// handleAddAccount implements AddAccount.
+func (s *Server) handleAddAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var account models.Account
+	if err := json.NewDecoder(r.Body).Decode(&account); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.ac.AddAccount(r.Context(), account); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+// handleRevokeToken implements RevokeToken.
+func (s *Server) handleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.ac.RevokeToken(req.Token); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+// handlePause implements PauseProcessing.
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	s.ac.Pause()
+	writeJSON(w, http.StatusOK, nil)
+}
+
+// handleResume implements ResumeProcessing.
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	s.ac.Resume()
+	writeJSON(w, http.StatusOK, nil)
+}
+
+// handleStats implements GetStats, enriched with the live figures the
+// embedded dashboard polls for: active in-flight requests, valid/invalid
+// token counts, a rough requests-per-second throughput, and uptime -
+// alongside the same email-status counts GetStats already returned.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.ac.GetStats()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	validTokens, invalidTokens, err := s.ac.TokenCounts()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"counts":         stats,
+		"valid_tokens":   validTokens,
+		"invalid_tokens": invalidTokens,
+	}
+
+	// ActiveRequests/StartTime live on the current LinkedInCrawler, which is
+	// nil between account rotations - the dashboard just sees zeros then.
+	if lc := s.ac.GetCrawler(); lc != nil {
+		uptime := time.Since(lc.StartTime)
+		resp["active_requests"] = atomic.LoadInt32(&lc.ActiveRequests)
+		resp["uptime_seconds"] = uptime.Seconds()
+
+		processed := stats[string(database.EmailStatusSuccessWithData)] +
+			stats[string(database.EmailStatusSuccessNoData)] +
+			stats[string(database.EmailStatusPermanentFailed)]
+		if uptime > 0 {
+			resp["requests_per_sec"] = float64(processed) / uptime.Seconds()
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleTokensReload implements the dashboard's "reload tokens" action,
+// re-reading config.TokensFilePath into the pool without restarting.
+func (s *Server) handleTokensReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.ac.ReloadTokens(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+// handleControlStop cancels the current LinkedInCrawler's request pipeline
+// (lc.Cancel) without tearing down the whole process - the crawler winds
+// down the same way it does when a token pool or account rotation cancels
+// it internally. A nil crawler (between rotations) is a no-op.
+func (s *Server) handleControlStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if lc := s.ac.GetCrawler(); lc != nil && lc.Cancel != nil {
+		lc.Cancel()
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+// handleStreamResults implements StreamResults as a Server-Sent Events
+// stream: there's no grpc/websocket transport available in this build, so
+// it polls GetEmailPage for newly-terminal emails past since_id and pushes
+// each one as an "event: result" SSE message, which any REST/grpc-gateway
+// client can consume the same way it would a streamed response.
+func (s *Server) handleStreamResults(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming unsupported"))
+		return
+	}
+
+	sinceID := int64(0)
+	if since := r.URL.Query().Get("since_id"); since != "" {
+		id, err := strconv.ParseInt(since, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid since_id %q", since))
+			return
+		}
+		sinceID = id
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	ctx := r.Context()
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			records, err := s.ac.GetDBStorage().EmailRepo.GetEmailPage("", sinceID, 100)
+			if err != nil {
+				logging.Logger.Warn("⚠️ api: StreamResults không thể đọc email mới", "error", err)
+				continue
+			}
+			for _, rec := range records {
+				if rec.Status == database.EmailStatusPending {
+					continue
+				}
+				payload, _ := json.Marshal(recordToProto(rec))
+				fmt.Fprintf(w, "event: result\ndata: %s\n\n", payload)
+				sinceID = rec.ID
+			}
+			if len(records) > 0 {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// streamPollInterval is how often handleStreamResults checks for newly
+// terminal emails.
+const streamPollInterval = 2 * time.Second
@@ -3,25 +3,137 @@ package auth
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
+
+	"linkedin-crawler/internal/models"
 )
 
-// BrowserManager handles Chrome browser automation
-type BrowserManager struct{}
+// BrowserManager is a pool of reusable Chrome contexts: Acquire hands a
+// caller one of cfg.PoolSize slots (spawning its Chrome context on first
+// use, then reusing it), Release returns it for the next caller, so
+// several logins can run concurrently instead of serially on a single
+// shared context.
+type BrowserManager struct {
+	cfg models.BrowserConfig
+
+	mu    sync.Mutex
+	slots []*browserSlot
+
+	// free is a free-list of slot indices; Acquire blocks on it, Release
+	// pushes back onto it.
+	free chan int
+}
+
+// browserSlot is one pool slot's lazily-created Chrome context.
+type browserSlot struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Session is a pool slot acquired via Acquire. Callers run chromedp
+// actions against Ctx and must call Release when done, even on error.
+type Session struct {
+	Ctx context.Context
+
+	bm   *BrowserManager
+	slot int
+}
+
+// NewBrowserManager returns a pool sized and configured per cfg. It spawns
+// no Chrome processes until Acquire is first called for a given slot.
+func NewBrowserManager(cfg models.BrowserConfig) *BrowserManager {
+	size := cfg.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+
+	bm := &BrowserManager{
+		cfg:   cfg,
+		slots: make([]*browserSlot, size),
+		free:  make(chan int, size),
+	}
+	for i := 0; i < size; i++ {
+		bm.free <- i
+	}
+	return bm
+}
 
-// NewBrowserManager creates a new BrowserManager instance
-func NewBrowserManager() *BrowserManager {
-	return &BrowserManager{}
+// Acquire waits for an idle pool slot (or ctx to be cancelled), creating
+// that slot's Chrome context on first use, and returns a Session wrapping
+// it. The caller must Release the Session when done.
+func (bm *BrowserManager) Acquire(ctx context.Context) (*Session, error) {
+	select {
+	case slot := <-bm.free:
+		browserCtx, err := bm.slotContext(ctx, slot)
+		if err != nil {
+			bm.free <- slot
+			return nil, err
+		}
+		return &Session{Ctx: browserCtx, bm: bm, slot: slot}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
-// CreateBrowserContext creates and configures a Chrome browser context
-func (bm *BrowserManager) CreateBrowserContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+// Release returns session's slot to the pool for the next Acquire. The
+// underlying Chrome context is left running (not cancelled) so its cookies
+// and login state carry over to whoever acquires the slot next; Shutdown
+// is what tears contexts down.
+func (bm *BrowserManager) Release(session *Session) {
+	if session == nil {
+		return
+	}
+	bm.free <- session.slot
+}
+
+// Shutdown cancels every pool slot's Chrome context. It's meant to be
+// called once, during process shutdown.
+func (bm *BrowserManager) Shutdown() {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	for _, s := range bm.slots {
+		if s != nil {
+			s.cancel()
+		}
+	}
+}
+
+// slotContext returns slot's Chrome context, creating it via
+// CreateBrowserContext the first time the slot is used.
+func (bm *BrowserManager) slotContext(ctx context.Context, slot int) (context.Context, error) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	if s := bm.slots[slot]; s != nil {
+		return s.ctx, nil
+	}
+
+	browserCtx, cancel, err := bm.CreateBrowserContext(ctx, slot)
+	if err != nil {
+		return nil, err
+	}
+	bm.slots[slot] = &browserSlot{ctx: browserCtx, cancel: cancel}
+	return browserCtx, nil
+}
+
+// CreateBrowserContext creates and configures slot's Chrome context:
+// headless mode, a remote debugging endpoint instead of a local process,
+// a per-slot proxy, and a persistent user-data-dir are all driven by the
+// BrowserConfig NewBrowserManager was built with.
+func (bm *BrowserManager) CreateBrowserContext(ctx context.Context, slot int) (context.Context, context.CancelFunc, error) {
+	if bm.cfg.RemoteDebuggingURL != "" {
+		allocCtx, allocCancel := chromedp.NewRemoteAllocator(ctx, bm.cfg.RemoteDebuggingURL)
+		return bm.newBrowserContext(allocCtx, allocCancel)
+	}
+
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", false),
+		chromedp.Flag("headless", bm.cfg.Headless),
 		chromedp.Flag("disable-blink-features", "AutomationControlled"),
 		chromedp.Flag("disable-infobars", true),
 		chromedp.Flag("no-sandbox", true),
@@ -33,22 +145,42 @@ func (bm *BrowserManager) CreateBrowserContext(ctx context.Context) (context.Con
 		chromedp.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36"),
 	)
 
-	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
+	if bm.cfg.UserDataDir != "" {
+		opts = append(opts, chromedp.UserDataDir(filepath.Join(bm.cfg.UserDataDir, fmt.Sprintf("slot-%d", slot))))
+	}
+	if proxyURL := bm.slotProxy(slot); proxyURL != "" {
+		opts = append(opts, chromedp.Flag("proxy-server", proxyURL))
+	}
+
+	allocCtx, allocCancel := chromedp.NewExecAllocator(ctx, opts...)
+	return bm.newBrowserContext(allocCtx, allocCancel)
+}
+
+// slotProxy returns the proxy assigned to slot, round-robin over
+// cfg.ProxyURLs, or "" when none are configured.
+func (bm *BrowserManager) slotProxy(slot int) string {
+	if len(bm.cfg.ProxyURLs) == 0 {
+		return ""
+	}
+	return bm.cfg.ProxyURLs[slot%len(bm.cfg.ProxyURLs)]
+}
+
+// newBrowserContext wraps allocCtx in a chromedp browser context and
+// enables network events, combining allocCancel with the browser's own
+// cancel into a single returned CancelFunc.
+func (bm *BrowserManager) newBrowserContext(allocCtx context.Context, allocCancel context.CancelFunc) (context.Context, context.CancelFunc, error) {
 	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
 
-	// Enable network events
 	if err := chromedp.Run(browserCtx, network.Enable()); err != nil {
-		cancel()
 		browserCancel()
+		allocCancel()
 		return nil, nil, fmt.Errorf("không enable được network events: %v", err)
 	}
 
-	// Return a combined cancel function
 	combinedCancel := func() {
 		browserCancel()
-		cancel()
+		allocCancel()
 	}
-
 	return browserCtx, combinedCancel, nil
 }
 
@@ -15,19 +15,44 @@ type LoginService struct {
 	browserManager *BrowserManager
 }
 
-// NewLoginService creates a new LoginService instance
-func NewLoginService() *LoginService {
+// NewLoginService creates a new LoginService instance, pooling Chrome
+// contexts per cfg so concurrent callers can log separate accounts in at
+// once instead of serializing on a single shared context.
+func NewLoginService(cfg models.BrowserConfig) *LoginService {
 	return &LoginService{
-		browserManager: NewBrowserManager(),
+		browserManager: NewBrowserManager(cfg),
 	}
 }
 
-// LoginToTeams performs login to Microsoft Teams
-func (ls *LoginService) LoginToTeams(ctx context.Context, account models.Account) error {
-	loginURL := "https://teams.microsoft.com/"
+// Shutdown cancels every pooled Chrome context. It's meant to be called
+// once, during process shutdown.
+func (ls *LoginService) Shutdown() {
+	ls.browserManager.Shutdown()
+}
 
+// LoginToTeams performs login to Microsoft Teams, acquiring a pool slot
+// for the duration of the call and releasing it when done (even on error)
+// so the next caller - possibly running concurrently against a different
+// account - can reuse it.
+func (ls *LoginService) LoginToTeams(ctx context.Context, account models.Account) error {
 	fmt.Printf("🔑 Đang xử lý account: %s\n", account.Email)
 
+	session, err := ls.browserManager.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("không thể lấy browser session: %w", err)
+	}
+	defer ls.browserManager.Release(session)
+
+	return ls.loginOn(session.Ctx, account)
+}
+
+// loginOn runs the Teams sign-in flow against an already-acquired Chrome
+// context. It's the shared implementation behind LoginToTeams and
+// TokenExtractor.extractToken, which also needs a CDP listener attached to
+// the same context before navigation starts.
+func (ls *LoginService) loginOn(ctx context.Context, account models.Account) error {
+	loginURL := "https://teams.microsoft.com/"
+
 	err := chromedp.Run(ctx,
 		chromedp.Navigate(loginURL),
 		chromedp.Sleep(3*time.Second),
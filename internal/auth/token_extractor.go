@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+
+	"linkedin-crawler/internal/models"
+)
+
+// tokenExtractTimeout bounds how long extractToken waits for one
+// account's login plus token capture before giving up on it.
+const tokenExtractTimeout = 90 * time.Second
+
+// TokenExtractor logs into each account via a pooled Chrome context and
+// harvests the bearer token Teams' own client sends once signed in, by
+// listening for its outgoing "Authorization" header over CDP rather than
+// reimplementing whatever endpoint issues it.
+type TokenExtractor struct {
+	loginService *LoginService
+}
+
+// NewTokenExtractor returns a TokenExtractor with its own single-slot,
+// headless browser pool, independent of any other LoginService the caller
+// may be running (e.g. AutoCrawler's account-keepalive login). Callers
+// that need a custom BrowserConfig (proxy pool, pool size, remote
+// debugging endpoint, ...) should use NewTokenExtractorWithConfig instead.
+func NewTokenExtractor() *TokenExtractor {
+	return NewTokenExtractorWithConfig(models.BrowserConfig{Headless: true})
+}
+
+// NewTokenExtractorWithConfig returns a TokenExtractor whose browser pool
+// is configured per cfg.
+func NewTokenExtractorWithConfig(cfg models.BrowserConfig) *TokenExtractor {
+	return &TokenExtractor{loginService: NewLoginService(cfg)}
+}
+
+// Shutdown cancels every pooled Chrome context. It's meant to be called
+// once, during process shutdown.
+func (te *TokenExtractor) Shutdown() {
+	te.loginService.Shutdown()
+}
+
+// ExtractTokensBatch logs into every account in turn and returns one
+// models.TokenResult each, in the same order as accounts. accountsFilePath
+// is accepted for parity with the accounts-file-driven callers in
+// internal/orchestrator but isn't otherwise used here - extraction only
+// needs the in-memory Account, not the file it was parsed from.
+func (te *TokenExtractor) ExtractTokensBatch(accounts []models.Account, accountsFilePath string) []models.TokenResult {
+	results := make([]models.TokenResult, len(accounts))
+	for i, account := range accounts {
+		token, err := te.extractToken(account)
+		results[i] = models.TokenResult{Account: account, Token: token, Error: err}
+	}
+	return results
+}
+
+// extractToken acquires a pool slot, attaches a CDP listener for the
+// first outgoing "Authorization: Bearer ..." header, then drives the
+// normal Teams sign-in flow (loginOn) against that same context. It
+// returns once a token is captured or ctx's tokenExtractTimeout expires.
+func (te *TokenExtractor) extractToken(account models.Account) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenExtractTimeout)
+	defer cancel()
+
+	session, err := te.loginService.browserManager.Acquire(ctx)
+	if err != nil {
+		return "", fmt.Errorf("không thể lấy browser session: %w", err)
+	}
+	defer te.loginService.browserManager.Release(session)
+
+	const bearerPrefix = "Bearer "
+	tokenCh := make(chan string, 1)
+	chromedp.ListenTarget(session.Ctx, func(ev interface{}) {
+		req, ok := ev.(*network.EventRequestWillBeSent)
+		if !ok {
+			return
+		}
+		header, ok := req.Request.Headers["Authorization"].(string)
+		if !ok || !strings.HasPrefix(header, bearerPrefix) {
+			return
+		}
+		select {
+		case tokenCh <- strings.TrimPrefix(header, bearerPrefix):
+		default:
+		}
+	})
+
+	loginErr := make(chan error, 1)
+	go func() { loginErr <- te.loginService.loginOn(session.Ctx, account) }()
+
+	select {
+	case token := <-tokenCh:
+		return token, nil
+	case err := <-loginErr:
+		if err != nil {
+			return "", err
+		}
+		// Login finished with no error but no token was ever observed;
+		// give the listener a last short window in case the request that
+		// carries it fires just after the chat conversation loads.
+		select {
+		case token := <-tokenCh:
+			return token, nil
+		case <-time.After(5 * time.Second):
+			return "", fmt.Errorf("không tìm thấy bearer token cho %s sau khi đăng nhập", account.Email)
+		}
+	case <-ctx.Done():
+		return "", fmt.Errorf("hết thời gian chờ token cho %s: %w", account.Email, ctx.Err())
+	}
+}
@@ -19,5 +19,53 @@ func DefaultConfig() models.Config {
 		MinTokens:        10,
 		MaxTokens:        10,
 		SleepDuration:    1 * time.Minute,
+		LeaseDuration:    5 * time.Minute,
+		Storage: models.StorageConfig{
+			Type:         "sqlite",
+			SQLitePath:   "crawler.db",
+			MaxOpenConns: 1,
+		},
+		Proxy: models.ProxyConfig{
+			FilePath:        "proxies.txt",
+			MinHealthy:      1,
+			RefreshInterval: 10 * time.Minute,
+		},
+		MetricsAddr: ":9090",
+
+		ProgressMode: "auto",
+
+		TokenRateLimit: models.RateLimitConfig{
+			RatePerSecond: 2.0,
+			Burst:         3.0,
+		},
+
+		CheckpointPath: "checkpoint.wal",
+
+		Outputs: []models.OutputSpec{
+			{Type: "text"},
+		},
+
+		Coordinator: "local",
+
+		// Notifier defaults to sending nothing configured (Kind ""), which
+		// notifier.New resolves to NullNotifier.
+		Notifier: models.NotifierParams{},
+
+		LeaderElection: "local",
+
+		ProfileBackend: "linkedin",
+
+		GCInterval:    10 * time.Minute,
+		EmailMaxStale: 1 * time.Hour,
+		TokenMaxStale: 30 * time.Minute,
+
+		DBGCInterval:          15 * time.Minute,
+		TokenFailureThreshold: 5,
+		TokenIdleTTL:          1 * time.Hour,
+		AccountRotationTTL:    2 * time.Hour,
+		EmailArchiveRetention: 24 * time.Hour,
+
+		LogSink:     "file",
+		LogFilePath: "crawler.log",
 	}
 }
@@ -0,0 +1,41 @@
+// Package coordinator provides leader election for fleets of cooperating
+// crawler processes sharing one workload (see orchestrator/coordinator for
+// the separate concern of leasing out the actual emails/accounts/tokens).
+// Exactly one worker should run phases that don't make sense to duplicate -
+// RetryHandler.RetryFailedEmails and StateManager.SaveStateOnShutdown - and
+// LeaderElector is how a worker finds out whether it's that one.
+//
+// Only the local backend is implemented here: real distributed election
+// (ZooKeeper sequential ephemeral znodes, or etcd's concurrency.Election)
+// is the natural next step for a multi-process fleet, but no ZooKeeper or
+// etcd client is vendored in this build and there's no network access to
+// add one, so New returns a clear error for any non-local URL instead of
+// faking the protocol - see zk.go and etcd.go.
+package coordinator
+
+import "context"
+
+// LeaderElector campaigns for leadership among cooperating workers and
+// reports whether this node currently holds it, so callers can gate
+// leader-only work behind IsLeader instead of duplicating it across every
+// worker.
+type LeaderElector interface {
+	// Campaign blocks until this node becomes leader or ctx is cancelled.
+	// The returned channel is closed the moment leadership is lost (the
+	// backing session expires, or Resign is called) - callers should treat
+	// it like ctx.Done(): stop leader-only work and, if they want to keep
+	// participating, Campaign again.
+	Campaign(ctx context.Context, workerID string) (lost <-chan struct{}, err error)
+
+	// Resign gives up leadership immediately, so a graceful shutdown fails
+	// over to the next worker right away instead of waiting for a session
+	// timeout to expire.
+	Resign(ctx context.Context) error
+
+	// IsLeader reports whether this node currently holds leadership.
+	IsLeader() bool
+
+	// Close releases any resources (connections, background goroutines)
+	// the elector holds.
+	Close() error
+}
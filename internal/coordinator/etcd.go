@@ -0,0 +1,13 @@
+package coordinator
+
+import "fmt"
+
+// newEtcdElector backs --leader-election=etcd://... URLs. A real backend
+// would use etcd's concurrency.Election (itself built on the same
+// sequential-key-plus-watch-predecessor pattern as a ZooKeeper election) -
+// no etcd client is vendored in this build and there's no network access
+// to add one, so this fails fast with a clear error naming what's missing,
+// the same honest-substitute approach used for zk.go.
+func newEtcdElector(rawURL string) (LeaderElector, error) {
+	return nil, fmt.Errorf("coordinator: etcd backend (%s) requires an etcd client, which isn't vendored in this build - use --leader-election=local (the default) for single-process mode", rawURL)
+}
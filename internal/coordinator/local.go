@@ -0,0 +1,66 @@
+package coordinator
+
+import (
+	"context"
+	"sync"
+)
+
+// localElector is the default, single-process backend: with no other
+// workers to contend with, this node is always the leader.
+type localElector struct {
+	mu   sync.Mutex
+	lost chan struct{}
+}
+
+func newLocalElector() *localElector {
+	return &localElector{}
+}
+
+// Campaign returns immediately - a lone worker never has to wait for
+// leadership. The returned channel only closes when ctx is cancelled or
+// Resign/Close is called, since there's no session to lose it to.
+func (e *localElector) Campaign(ctx context.Context, workerID string) (<-chan struct{}, error) {
+	e.mu.Lock()
+	e.lost = make(chan struct{})
+	lost := e.lost
+	e.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		e.closeLost(lost)
+	}()
+	return lost, nil
+}
+
+func (e *localElector) closeLost(lost chan struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if lost == nil {
+		return
+	}
+	select {
+	case <-lost:
+	default:
+		close(lost)
+	}
+}
+
+// Resign gives up leadership immediately. A no-op in practice since
+// there's no one else to fail over to, but it still closes the channel
+// Campaign returned so callers selecting on it behave the same as a real
+// backend.
+func (e *localElector) Resign(ctx context.Context) error {
+	e.mu.Lock()
+	lost := e.lost
+	e.mu.Unlock()
+	e.closeLost(lost)
+	return nil
+}
+
+func (e *localElector) IsLeader() bool {
+	return true
+}
+
+func (e *localElector) Close() error {
+	return e.Resign(context.Background())
+}
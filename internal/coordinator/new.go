@@ -0,0 +1,23 @@
+package coordinator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// New builds a LeaderElector from spec, the --leader-election flag's
+// value. Empty or "local" selects the default single-process backend; a
+// "zk://" or "etcd://" URL selects a (currently unimplemented, see zk.go
+// and etcd.go) distributed backend.
+func New(spec string) (LeaderElector, error) {
+	if spec == "" || spec == "local" {
+		return newLocalElector(), nil
+	}
+	if strings.HasPrefix(spec, "zk://") {
+		return newZKElector(spec)
+	}
+	if strings.HasPrefix(spec, "etcd://") {
+		return newEtcdElector(spec)
+	}
+	return nil, fmt.Errorf("coordinator: unrecognized --leader-election value %q (expected \"local\", a zk:// URL, or an etcd:// URL)", spec)
+}
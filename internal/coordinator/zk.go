@@ -0,0 +1,15 @@
+package coordinator
+
+import "fmt"
+
+// newZKElector backs --leader-election=zk://... URLs. A real backend would
+// create a sequential ephemeral znode under an election path and watch its
+// predecessor, promoting this node to leader once it's lowest-numbered - no
+// ZooKeeper client is vendored in this build and there's no network access
+// to add one, so rather than fake that protocol over a raw TCP connection,
+// this fails fast with a clear error naming what's missing - the same
+// honest-substitute approach used for the redis coordinator backend (see
+// orchestrator/coordinator/redis.go).
+func newZKElector(rawURL string) (LeaderElector, error) {
+	return nil, fmt.Errorf("coordinator: zk backend (%s) requires a ZooKeeper client, which isn't vendored in this build - use --leader-election=local (the default) for single-process mode", rawURL)
+}
@@ -0,0 +1,117 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+
+	"linkedin-crawler/internal/models"
+)
+
+// ProfileResult is what a ProfileBackend reports back for one FetchProfile
+// call - everything attemptOnce needs to record stats, write to a sink, and
+// attribute the attempt to the token/request that served it.
+type ProfileResult struct {
+	Profile    models.ProfileData
+	HasProfile bool
+	StatusCode int
+	Token      string
+	Body       []byte
+	Err        error
+}
+
+// ProfileBackend fetches a profile for email. It returns a channel - the Go
+// stand-in for a typed future - rather than a bare return value, so a
+// caller can select it alongside ctx.Done() instead of blocking
+// unconditionally; exactly one ProfileResult is ever sent, then the
+// channel is closed.
+//
+// LinkedInAPIBackend is the only backend wired into the steady-state crawl
+// today. MockBackend lets the orchestrator/batch-processing pipeline be
+// exercised without hitting LinkedIn. RocketReachBackend is a named
+// placeholder for a second provider, not implemented yet.
+type ProfileBackend interface {
+	FetchProfile(ctx context.Context, lc *models.LinkedInCrawler, email string) <-chan ProfileResult
+}
+
+// LinkedInAPIBackend is the production ProfileBackend: it's QueryService's
+// existing token/proxy-aware retry logic, adapted to the future-style
+// interface.
+type LinkedInAPIBackend struct {
+	queryService     *QueryService
+	profileExtractor *ProfileExtractor
+}
+
+// NewLinkedInAPIBackend wraps qs as a ProfileBackend.
+func NewLinkedInAPIBackend(qs *QueryService) *LinkedInAPIBackend {
+	return &LinkedInAPIBackend{queryService: qs, profileExtractor: NewProfileExtractor()}
+}
+
+// FetchProfile runs the request on its own goroutine and reports the
+// result on the returned channel, so the caller can select on it alongside
+// ctx.Done() rather than blocking past a cancelled context.
+func (b *LinkedInAPIBackend) FetchProfile(ctx context.Context, lc *models.LinkedInCrawler, email string) <-chan ProfileResult {
+	ch := make(chan ProfileResult, 1)
+	go func() {
+		defer close(ch)
+
+		hasProfile, body, statusCode, token, err := b.queryService.QueryProfileWithRetryLogic(lc, ctx, email)
+		result := ProfileResult{HasProfile: hasProfile, StatusCode: statusCode, Token: token, Body: body, Err: err}
+
+		if statusCode == 200 && hasProfile {
+			if profile, parseErr := b.profileExtractor.ExtractProfileData(body); parseErr == nil {
+				result.Profile = profile
+			}
+		}
+
+		ch <- result
+	}()
+	return ch
+}
+
+// MockBackend satisfies ProfileBackend without making any network calls,
+// so the crawl pipeline can be exercised against canned responses instead
+// of LinkedIn. Responses maps an email to the result FetchProfile reports
+// for it; an email with no entry gets Default.
+type MockBackend struct {
+	Responses map[string]ProfileResult
+	Default   ProfileResult
+}
+
+// FetchProfile looks up email in Responses (falling back to Default) and
+// reports it immediately - there's nothing to wait on, but the channel
+// still carries exactly one result so MockBackend is a drop-in
+// ProfileBackend.
+func (b *MockBackend) FetchProfile(ctx context.Context, lc *models.LinkedInCrawler, email string) <-chan ProfileResult {
+	ch := make(chan ProfileResult, 1)
+	result, ok := b.Responses[email]
+	if !ok {
+		result = b.Default
+	}
+	ch <- result
+	close(ch)
+	return ch
+}
+
+// NewBackend builds the ProfileBackend named by spec: "" or "linkedin"
+// (the default, wrapping qs) or "rocketreach" (a stub, see
+// NewRocketReachBackend). Mirrors coordinator.New's dispatch-on-string
+// convention.
+func NewBackend(spec string, qs *QueryService) (ProfileBackend, error) {
+	switch spec {
+	case "", "linkedin":
+		return NewLinkedInAPIBackend(qs), nil
+	case "rocketreach":
+		return NewRocketReachBackend("")
+	default:
+		return nil, fmt.Errorf("crawler: unknown profile backend %q", spec)
+	}
+}
+
+// NewRocketReachBackend would back a RocketReach-sourced ProfileBackend, a
+// second provider alongside LinkedInAPIBackend. Not implemented yet - no
+// RocketReach client is vendored in this build and there's no network
+// access to add one - but it's named here so config can reference it today
+// and get a clear error instead of silently falling back to LinkedIn.
+func NewRocketReachBackend(apiKey string) (ProfileBackend, error) {
+	return nil, fmt.Errorf("crawler: RocketReach backend not implemented yet - no client vendored in this build, use \"linkedin\" (the default)")
+}
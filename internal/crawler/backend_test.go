@@ -0,0 +1,42 @@
+package crawler
+
+import (
+	"context"
+	"testing"
+
+	"linkedin-crawler/internal/models"
+)
+
+func TestMockBackendFetchProfile(t *testing.T) {
+	backend := &MockBackend{
+		Responses: map[string]ProfileResult{
+			"known@example.com": {HasProfile: true, StatusCode: 200, Token: "tok-1"},
+		},
+		Default: ProfileResult{HasProfile: false, StatusCode: 404},
+	}
+
+	result := <-backend.FetchProfile(context.Background(), &models.LinkedInCrawler{}, "known@example.com")
+	if !result.HasProfile || result.StatusCode != 200 || result.Token != "tok-1" {
+		t.Fatalf("known email: got %+v", result)
+	}
+
+	result = <-backend.FetchProfile(context.Background(), &models.LinkedInCrawler{}, "unknown@example.com")
+	if result.HasProfile || result.StatusCode != 404 {
+		t.Fatalf("unknown email should fall back to Default: got %+v", result)
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	if _, err := NewBackend("", nil); err != nil {
+		t.Errorf("empty spec should default to linkedin: %v", err)
+	}
+	if _, err := NewBackend("linkedin", nil); err != nil {
+		t.Errorf("linkedin spec: %v", err)
+	}
+	if _, err := NewBackend("rocketreach", nil); err == nil {
+		t.Errorf("rocketreach is an unimplemented stub, expected an error")
+	}
+	if _, err := NewBackend("bogus", nil); err == nil {
+		t.Errorf("unknown spec should error")
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"golang.org/x/sync/semaphore"
 
+	"linkedin-crawler/internal/crawler/ratelimit"
 	"linkedin-crawler/internal/models"
 )
 
@@ -87,7 +88,11 @@ func New(config models.Config, outputFilePath string) (*models.LinkedInCrawler,
 		BufferedWriter:    bufferedWriter,
 		StartTime:         time.Now(),
 		InvalidTokens:     make(map[string]bool),
+		TokenStats:        make(map[string]*models.TokenState),
+		RateLimitStats:    make(map[string]*ratelimit.TokenState),
 		TokensFilePath:    config.TokensFilePath,
+		InvalidProxies:    make(map[string]bool),
+		ProxyStats:        make(map[string]*models.ProxyState),
 		RateLimitedEmails: []string{},
 		RequestSemaphore:  semaphore.NewWeighted(config.MaxConcurrency),
 		RequestTicker:     requestTicker,
@@ -59,28 +59,3 @@ func (pe *ProfileExtractor) ExtractProfileData(responseJSON []byte) (models.Prof
 
 	return profile, nil
 }
-
-// WriteProfileToFile writes profile data to output file
-func (pe *ProfileExtractor) WriteProfileToFile(lc *models.LinkedInCrawler, email string, profile models.ProfileData) error {
-	lc.OutputMutex.Lock()
-	defer lc.OutputMutex.Unlock()
-
-	// APPEND mode - ghi thêm vào file hit.txt (KHÔNG ghi đè)
-	line := fmt.Sprintf("%s|%s|%s|%s|%s\n", email, profile.User, profile.LinkedInURL, profile.Location, profile.ConnectionCount)
-	_, err := lc.BufferedWriter.WriteString(line)
-	if err != nil {
-		return fmt.Errorf("failed to write to output file: %w", err)
-	}
-
-	// Force flush để đảm bảo data được ghi ngay lập tức
-	if flushErr := lc.BufferedWriter.Flush(); flushErr != nil {
-		return fmt.Errorf("failed to flush output file: %w", flushErr)
-	}
-
-	// Force sync to disk để tránh mất data khi crash
-	if syncErr := lc.OutputFile.Sync(); syncErr != nil {
-		return fmt.Errorf("failed to sync output file: %w", syncErr)
-	}
-
-	return nil
-}
@@ -0,0 +1,236 @@
+package crawler
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"linkedin-crawler/internal/models"
+)
+
+const (
+	proxyCooldownBase        = 30 * time.Second
+	proxyCooldownCap         = 10 * time.Minute
+	proxyInvalidateThreshold = 5 // consecutive faults before a proxy is pulled from rotation
+)
+
+// ProxyManager handles proxy rotation and health tracking, mirroring
+// TokenManager so a banned IP burns a proxy's cooldown instead of a good
+// token's.
+type ProxyManager struct {
+	mutex   sync.Mutex
+	clients map[string]*http.Client
+}
+
+// NewProxyManager creates a new ProxyManager instance
+func NewProxyManager() *ProxyManager {
+	return &ProxyManager{clients: make(map[string]*http.Client)}
+}
+
+// proxyState returns (creating if necessary) the ProxyState for proxyURL.
+// Callers must hold lc.ProxyMutex.
+func proxyState(lc *models.LinkedInCrawler, proxyURL string) *models.ProxyState {
+	if lc.ProxyStats == nil {
+		lc.ProxyStats = make(map[string]*models.ProxyState)
+	}
+	s, ok := lc.ProxyStats[proxyURL]
+	if !ok {
+		s = &models.ProxyState{}
+		lc.ProxyStats[proxyURL] = s
+	}
+	return s
+}
+
+// GetProxy returns a proxy drawn from the valid, not-on-cooldown pool,
+// weighted towards lower observed latency. It returns "" (go direct, no
+// proxy) when the pool is empty or every proxy is invalid.
+func (pm *ProxyManager) GetProxy(lc *models.LinkedInCrawler) string {
+	lc.ProxyMutex.Lock()
+	defer lc.ProxyMutex.Unlock()
+
+	if len(lc.Proxies) == 0 {
+		return ""
+	}
+
+	now := time.Now()
+	type candidate struct {
+		url    string
+		weight float64
+	}
+	var candidates []candidate
+	var fallback []string
+
+	for _, p := range lc.Proxies {
+		if lc.InvalidProxies[p] {
+			continue
+		}
+		fallback = append(fallback, p)
+
+		s := proxyState(lc, p)
+		if !s.CooldownUntil.IsZero() && now.Before(s.CooldownUntil) {
+			continue
+		}
+
+		weight := 1 / (1 + float64(s.AvgLatencyMs)/1000)
+		candidates = append(candidates, candidate{url: p, weight: weight})
+	}
+
+	if len(candidates) == 0 {
+		// Every valid proxy is cooling down; fall back to any valid proxy
+		// rather than stalling the whole pool on a temporary cooldown.
+		if len(fallback) > 0 {
+			return fallback[rand.Intn(len(fallback))]
+		}
+		return ""
+	}
+
+	total := 0.0
+	for _, c := range candidates {
+		total += c.weight
+	}
+
+	pick := rand.Float64() * total
+	for _, c := range candidates {
+		pick -= c.weight
+		if pick <= 0 {
+			return c.url
+		}
+	}
+	return candidates[len(candidates)-1].url
+}
+
+// RecordSuccess updates a proxy's average latency after a successful
+// request and clears any cooldown.
+func (pm *ProxyManager) RecordSuccess(lc *models.LinkedInCrawler, proxyURL string, latency time.Duration) {
+	if proxyURL == "" {
+		return
+	}
+
+	lc.ProxyMutex.Lock()
+	defer lc.ProxyMutex.Unlock()
+
+	s := proxyState(lc, proxyURL)
+	const alpha = 0.2
+	latencyMs := latency.Milliseconds()
+	if s.AvgLatencyMs == 0 {
+		s.AvgLatencyMs = latencyMs
+	} else {
+		s.AvgLatencyMs = int64(alpha*float64(latencyMs) + (1-alpha)*float64(s.AvgLatencyMs))
+	}
+	s.ConsecutiveFailures = 0
+	s.CooldownUntil = time.Time{}
+}
+
+// RecordFault applies exponential backoff (capped at proxyCooldownCap) to a
+// proxy after a connection error or TLS failure, and pulls it out of
+// rotation once it has failed proxyInvalidateThreshold times in a row -
+// either case points at the egress IP, not the token, so the token keeps
+// its own stats untouched.
+func (pm *ProxyManager) RecordFault(lc *models.LinkedInCrawler, proxyURL string) {
+	if proxyURL == "" {
+		return
+	}
+
+	lc.ProxyMutex.Lock()
+	defer lc.ProxyMutex.Unlock()
+
+	s := proxyState(lc, proxyURL)
+	s.ConsecutiveFailures++
+
+	if s.ConsecutiveFailures >= proxyInvalidateThreshold {
+		lc.InvalidProxies[proxyURL] = true
+		return
+	}
+
+	cooldown := proxyCooldownBase << uint(minInt(s.ConsecutiveFailures-1, 10))
+	if cooldown > proxyCooldownCap || cooldown <= 0 {
+		cooldown = proxyCooldownCap
+	}
+	s.CooldownUntil = time.Now().Add(cooldown)
+}
+
+// GetValidProxyCount returns the number of proxies not yet pulled from
+// rotation (cooling-down proxies still count - they come back after their
+// cooldown expires).
+func (pm *ProxyManager) GetValidProxyCount(lc *models.LinkedInCrawler) int {
+	lc.ProxyMutex.Lock()
+	defer lc.ProxyMutex.Unlock()
+
+	count := 0
+	for _, p := range lc.Proxies {
+		if !lc.InvalidProxies[p] {
+			count++
+		}
+	}
+	return count
+}
+
+// ClientFor returns an http.Client that routes through proxyURL, reusing
+// lc.Client's transport settings (timeouts, connection limits) so a proxied
+// request behaves like a direct one apart from the egress IP. Pass "" to
+// get lc.Client itself (no proxy).
+func (pm *ProxyManager) ClientFor(lc *models.LinkedInCrawler, proxyURL string) (*http.Client, error) {
+	if proxyURL == "" {
+		return lc.Client, nil
+	}
+
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	if client, ok := pm.clients[proxyURL]; ok {
+		return client, nil
+	}
+
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url %q: %w", proxyURL, err)
+	}
+
+	switch parsed.Scheme {
+	case "http", "https":
+		// Supported directly via http.Transport.Proxy below.
+	default:
+		// SOCKS5 needs golang.org/x/net/proxy, which isn't vendored in
+		// this build; fail clearly instead of silently going direct.
+		return nil, fmt.Errorf("proxy scheme %q is not supported in this build (golang.org/x/net/proxy is not vendored)", parsed.Scheme)
+	}
+
+	transport := &http.Transport{
+		Proxy:                  http.ProxyURL(parsed),
+		MaxIdleConns:           50,
+		MaxIdleConnsPerHost:    50,
+		MaxConnsPerHost:        50,
+		IdleConnTimeout:        30 * time.Second,
+		ForceAttemptHTTP2:      true,
+		MaxResponseHeaderBytes: 1 << 20,
+		ResponseHeaderTimeout:  10 * time.Second,
+		ExpectContinueTimeout:  1 * time.Second,
+	}
+	if base, ok := lc.Client.Transport.(*http.Transport); ok {
+		transport.MaxIdleConns = base.MaxIdleConns
+		transport.MaxIdleConnsPerHost = base.MaxIdleConnsPerHost
+		transport.MaxConnsPerHost = base.MaxConnsPerHost
+		transport.IdleConnTimeout = base.IdleConnTimeout
+		transport.ForceAttemptHTTP2 = base.ForceAttemptHTTP2
+		transport.MaxResponseHeaderBytes = base.MaxResponseHeaderBytes
+		transport.ResponseHeaderTimeout = base.ResponseHeaderTimeout
+		transport.ExpectContinueTimeout = base.ExpectContinueTimeout
+	}
+
+	client := &http.Client{
+		Timeout:   lc.Client.Timeout,
+		Transport: transport,
+	}
+	pm.clients[proxyURL] = client
+	return client, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -5,36 +5,98 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 
+	"linkedin-crawler/internal/crawler/ratelimit"
+	"linkedin-crawler/internal/events"
+	"linkedin-crawler/internal/metrics"
 	"linkedin-crawler/internal/models"
 	"linkedin-crawler/internal/storage"
+	"linkedin-crawler/internal/utils"
 )
 
 // QueryService handles LinkedIn profile queries
 type QueryService struct {
 	tokenManager     *TokenManager
+	proxyManager     *ProxyManager
 	profileExtractor *ProfileExtractor
 	tokenStorage     *storage.TokenStorage
+
+	// eventBus and remainingEmails are nil unless SetEventBus was called -
+	// the zero value keeps every other NewQueryService call site (the
+	// validator's token-probing instances, chiefly) silent rather than
+	// emitting events for requests that aren't part of the real crawl.
+	eventBus        *events.Bus
+	remainingEmails func() int
+
+	// scheduleRetry is nil unless SetRetryScheduler was called. It's wired
+	// to EmailRepository.ScheduleRetry so an email that hits 429 gets a
+	// persisted next_attempt_at matching the in-process backoff, and still
+	// waits out its cooldown if the process restarts before retrying.
+	scheduleRetry func(email string, delay time.Duration)
+}
+
+// SetEventBus wires qs to emit crawl-lifecycle events to bus, with
+// remainingEmails called (from EmailRepository.CountRemainingEmails) to
+// stamp each event with how much work is left. It's meant to be called
+// once, on the single QueryService BatchProcessor builds for the real
+// crawl - not on the QueryService instances ValidatorService constructs to
+// probe token validity, which should stay quiet.
+func (qs *QueryService) SetEventBus(bus *events.Bus, remainingEmails func() int) {
+	qs.eventBus = bus
+	qs.remainingEmails = remainingEmails
+}
+
+// SetRetryScheduler wires qs to persist a retry delay for an email via
+// schedule (EmailRepository.ScheduleRetry) whenever it hits 429/999, so a
+// restart between the rate limit and the in-process retry doesn't lose the
+// cooldown. Like SetEventBus, it's meant to be called once, on the real
+// crawl's QueryService only.
+func (qs *QueryService) SetRetryScheduler(schedule func(email string, delay time.Duration)) {
+	qs.scheduleRetry = schedule
+}
+
+func (qs *QueryService) emit(eventType events.EventType, email string, statusCode int) {
+	if qs.eventBus == nil {
+		return
+	}
+	remaining := -1
+	if qs.remainingEmails != nil {
+		remaining = qs.remainingEmails()
+	}
+	qs.eventBus.Emit(events.Event{
+		Type:            eventType,
+		Email:           email,
+		StatusCode:      statusCode,
+		RemainingEmails: remaining,
+		Ts:              time.Now(),
+	})
 }
 
-// NewQueryService creates a new QueryService instance
-func NewQueryService() *QueryService {
+// NewQueryService creates a new QueryService instance whose token manager
+// paces each token per rl (requests/sec and burst).
+func NewQueryService(rl models.RateLimitConfig) *QueryService {
 	return &QueryService{
-		tokenManager:     &TokenManager{},
+		tokenManager:     NewTokenManager(rl),
+		proxyManager:     NewProxyManager(),
 		profileExtractor: NewProfileExtractor(),
 		tokenStorage:     storage.NewTokenStorage(),
 	}
 }
 
-// QueryProfileWithRetryLogic queries LinkedIn profile with retry logic and token switching
-func (qs *QueryService) QueryProfileWithRetryLogic(lc *models.LinkedInCrawler, ctx context.Context, email string) (bool, []byte, int, error) {
+// QueryProfileWithRetryLogic queries LinkedIn profile with retry logic and
+// token switching. The returned token is whichever one ultimately served
+// the request (it may differ from the first one acquired, if that one got
+// rate-limited or invalidated mid-call), for callers that want to attribute
+// the attempt to a specific token.
+func (qs *QueryService) QueryProfileWithRetryLogic(lc *models.LinkedInCrawler, ctx context.Context, email string) (bool, []byte, int, string, error) {
 	if qs.tokenManager.AreAllTokensFailed(lc) {
-		return false, nil, 0, fmt.Errorf("all tokens have failed")
+		return false, nil, 0, "", fmt.Errorf("all tokens have failed")
 	}
 
 	// Wait for rate limit token (requests per second max)
@@ -42,12 +104,12 @@ func (qs *QueryService) QueryProfileWithRetryLogic(lc *models.LinkedInCrawler, c
 	case <-lc.RequestChan:
 		// Got permission to make request
 	case <-ctx.Done():
-		return false, nil, 0, ctx.Err()
+		return false, nil, 0, "", ctx.Err()
 	}
 
 	// Acquire semaphore to limit concurrent requests
 	if err := lc.RequestSemaphore.Acquire(ctx, 1); err != nil {
-		return false, nil, 0, err
+		return false, nil, 0, "", err
 	}
 
 	// Track active requests
@@ -59,34 +121,58 @@ func (qs *QueryService) QueryProfileWithRetryLogic(lc *models.LinkedInCrawler, c
 		atomic.AddInt32(&lc.ActiveRequests, -1)
 	}()
 
-	// Thử với token đầu tiên
-	token := qs.tokenManager.GetToken(lc)
-	hasProfile, body, statusCode, err := qs.doQueryProfile(lc, ctx, email, token)
+	// Acquire a token that currently passes its rate limiter and circuit
+	// breaker, rather than round-robin, so a cooling-down token doesn't
+	// burn a request it would just get 429'd on.
+	token, err := qs.tokenManager.Acquire(ctx, lc)
+	if err != nil {
+		return false, nil, 0, "", err
+	}
+	finalToken := token
+	hasProfile, body, statusCode, err := qs.queryAndRecord(lc, ctx, email, token)
+
+	// Xử lý logic token switching đặc biệt cho 429/999
+	if statusCode == 429 || statusCode == 999 {
+		// Persist the token's adaptive cooldown against this email so a
+		// restart between now and the in-process retry below still
+		// respects it (see SetRetryScheduler).
+		if qs.scheduleRetry != nil {
+			if delay := time.Until(qs.tokenManager.Stats(lc, token).BackoffUntil); delay > 0 {
+				qs.scheduleRetry(email, delay)
+			}
+		}
 
-	// Xử lý logic token switching đặc biệt cho 429
-	if statusCode == 429 {
 		activeTokenCount := qs.tokenManager.GetValidTokenCount(lc)
 
 		if activeTokenCount > 1 {
-			// Có nhiều hơn 1 token active → Chuyển sang token khác
-			fmt.Printf("🔄 Token bị 429, chuyển sang token khác (có %d tokens active)\n", activeTokenCount)
-
-			// Đánh dấu token hiện tại là tạm thời invalid (không xóa khỏi file)
-			qs.tokenManager.MarkTokenAsInvalid(lc, token)
+			// Có nhiều hơn 1 token active → Chuyển sang token khác, token
+			// hiện tại vào cooldown thay vì bị loại bỏ hẳn
+			fmt.Printf("🔄 Token bị rate limit (status %d), chuyển sang token khác (có %d tokens active)\n", statusCode, activeTokenCount)
 
 			// Thử với token khác
-			newToken := qs.tokenManager.GetToken(lc)
-			if newToken != "" && newToken != token {
-				hasProfile, body, statusCode, err = qs.doQueryProfile(lc, ctx, email, newToken)
+			newToken, acquireErr := qs.tokenManager.Acquire(ctx, lc)
+			if acquireErr == nil && newToken != "" && newToken != token {
+				hasProfile, body, statusCode, err = qs.queryAndRecord(lc, ctx, email, newToken)
+				finalToken = newToken
 			}
 		} else {
-			time.Sleep(1 * time.Second)
-			// Thử lại với cùng token
-			hasProfile, body, statusCode, err = qs.doQueryProfile(lc, ctx, email, token)
+			// Chỉ còn một token: đợi đúng thời gian cooldown thích ứng của
+			// nó (thay vì sleep cố định 1s) rồi thử lại với cùng token.
+			wait := time.Until(qs.tokenManager.Stats(lc, token).BackoffUntil)
+			if wait <= 0 {
+				wait = 250 * time.Millisecond
+			}
+			select {
+			case <-ctx.Done():
+				return false, nil, statusCode, finalToken, ctx.Err()
+			case <-time.After(wait):
+			}
+			hasProfile, body, statusCode, err = qs.queryAndRecord(lc, ctx, email, token)
 		}
-	} else if statusCode == 401 || statusCode == 424 {
+	} else if statusCode == 401 || statusCode == 403 || statusCode == 424 {
 		// Xóa token không hợp lệ khỏi file
 		qs.tokenManager.MarkTokenAsInvalid(lc, token)
+		qs.emit(events.TokenInvalidated, email, statusCode)
 
 		if err := qs.tokenStorage.RemoveTokenFromFile(lc.TokensFilePath, token); err != nil {
 			fmt.Printf("⚠️ Không thể xóa token khỏi file: %v\n", err)
@@ -96,26 +182,100 @@ func (qs *QueryService) QueryProfileWithRetryLogic(lc *models.LinkedInCrawler, c
 
 		// Kiểm tra xem còn token hợp lệ không
 		if qs.tokenManager.CheckIfAllTokensInvalid(lc) {
-			return false, nil, statusCode, fmt.Errorf("all tokens have failed")
+			qs.emit(events.AllTokensFailed, email, statusCode)
+			return false, nil, statusCode, "", fmt.Errorf("all tokens have failed")
 		}
 
 		// Thử với token khác
-		newToken := qs.tokenManager.GetToken(lc)
-		if newToken != "" {
-			hasProfile, body, statusCode, err = qs.doQueryProfile(lc, ctx, email, newToken)
+		newToken, acquireErr := qs.tokenManager.Acquire(ctx, lc)
+		if acquireErr == nil && newToken != "" {
+			hasProfile, body, statusCode, err = qs.queryAndRecord(lc, ctx, email, newToken)
+			finalToken = newToken
 		}
 	}
 
+	return hasProfile, body, statusCode, finalToken, err
+}
+
+// queryAndRecord performs the request, choosing a (token, proxy) pair
+// jointly so a banned IP doesn't burn the token's stats: success closes the
+// token's breaker and updates both the token's and the proxy's
+// EWMA/latency, 429/999 opens the token's circuit breaker for an
+// exponentially-growing cooldown (the rate limit is tied to the token), a
+// connection error or TLS failure opens a cooldown on the proxy only, and a
+// 5xx from LinkedIn itself gets a decorrelated-jitter backoff on the token
+// alone.
+func (qs *QueryService) queryAndRecord(lc *models.LinkedInCrawler, ctx context.Context, email, token string) (bool, []byte, int, error) {
+	proxyURL := qs.proxyManager.GetProxy(lc)
+
+	start := time.Now()
+	hasProfile, body, statusCode, err := qs.doQueryProfile(lc, ctx, email, token, proxyURL)
+	latency := time.Since(start)
+	metrics.RequestLatencySeconds.Observe(latency.Seconds())
+
+	if statusCode == 0 && err != nil {
+		// No response at all: dial/TLS failure against the proxy, not a
+		// token problem.
+		qs.proxyManager.RecordFault(lc, proxyURL)
+		metrics.RecordTokenOutcome(utils.TokenFingerprint(token), false)
+		return hasProfile, body, statusCode, err
+	}
+
+	metrics.StatusCodeTotal.Inc(strconv.Itoa(statusCode))
+
+	switch {
+	case statusCode == 200:
+		qs.tokenManager.RecordSuccess(lc, token, latency)
+		qs.proxyManager.RecordSuccess(lc, proxyURL, latency)
+		metrics.RecordTokenOutcome(utils.TokenFingerprint(token), true)
+		if hasProfile {
+			qs.emit(events.ProfileHit, email, statusCode)
+		} else {
+			qs.emit(events.ProfileMiss, email, statusCode)
+		}
+	case statusCode == 429 || statusCode == 999:
+		qs.tokenManager.RecordRateLimit(lc, token)
+		metrics.RecordTokenOutcome(utils.TokenFingerprint(token), false)
+		qs.emit(events.TokenRateLimited, email, statusCode)
+	case statusCode >= 500 && statusCode < 600:
+		qs.tokenManager.RecordServerError(lc, token)
+		metrics.RecordTokenOutcome(utils.TokenFingerprint(token), false)
+	default:
+		metrics.RecordTokenOutcome(utils.TokenFingerprint(token), false)
+	}
+
 	return hasProfile, body, statusCode, err
 }
 
-// DoQueryProfile performs the actual HTTP request to LinkedIn API (exported method)
+// TokenStats returns a snapshot of token's rate-limit/circuit-breaker state,
+// along with its adaptive-selection success rate, for display by the
+// progress reporter.
+func (qs *QueryService) TokenStats(lc *models.LinkedInCrawler, token string) (rlStats ratelimit.Stats, successRate float64) {
+	lc.TokenMutex.Lock()
+	successRate = 1
+	if s, ok := lc.TokenStats[token]; ok {
+		successRate = s.SuccessEWMA
+	}
+	lc.TokenMutex.Unlock()
+
+	return qs.tokenManager.Stats(lc, token), successRate
+}
+
+// DoQueryProfile performs the actual HTTP request to LinkedIn API (exported
+// method), going direct (no proxy) - used by ValidatorService, which is
+// checking token validity rather than running the steady-state crawl.
 func (qs *QueryService) DoQueryProfile(lc *models.LinkedInCrawler, ctx context.Context, email, token string) (bool, []byte, int, error) {
-	return qs.doQueryProfile(lc, ctx, email, token)
+	return qs.doQueryProfile(lc, ctx, email, token, "")
 }
 
-// doQueryProfile performs the actual HTTP request to LinkedIn API
-func (qs *QueryService) doQueryProfile(lc *models.LinkedInCrawler, ctx context.Context, email, token string) (bool, []byte, int, error) {
+// doQueryProfile performs the actual HTTP request to LinkedIn API, through
+// proxyURL when set or directly when it's "".
+func (qs *QueryService) doQueryProfile(lc *models.LinkedInCrawler, ctx context.Context, email, token, proxyURL string) (bool, []byte, int, error) {
+	client, err := qs.proxyManager.ClientFor(lc, proxyURL)
+	if err != nil {
+		return false, nil, 0, err
+	}
+
 	authHeader := "Bearer " + token
 
 	rootCorrelationID := uuid.New().String()
@@ -146,7 +306,7 @@ func (qs *QueryService) doQueryProfile(lc *models.LinkedInCrawler, ctx context.C
 	req.Header.Add("Connection", "keep-alive")
 	req.Header.Add("X-LPCVersion", "1.20210418.1.0")
 
-	resp, err := lc.Client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return false, nil, 0, err
 	}
@@ -157,10 +317,14 @@ func (qs *QueryService) doQueryProfile(lc *models.LinkedInCrawler, ctx context.C
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusUnauthorized {
 			return false, nil, statusCode, fmt.Errorf("token authentication failed (401 Unauthorized): %s", resp.Status)
+		} else if resp.StatusCode == http.StatusForbidden {
+			return false, nil, statusCode, fmt.Errorf("token authentication failed (403 Forbidden): %s", resp.Status)
 		} else if resp.StatusCode == 424 {
 			return false, nil, statusCode, fmt.Errorf("token dependency failed (424 Failed Dependency): %s", resp.Status)
 		} else if resp.StatusCode == 429 {
 			return false, nil, statusCode, fmt.Errorf("rate limited (429 Too Many Requests): %s", resp.Status)
+		} else if resp.StatusCode == 999 {
+			return false, nil, statusCode, fmt.Errorf("rate limited (999): %s", resp.Status)
 		} else if resp.StatusCode == 500 {
 			return false, nil, statusCode, fmt.Errorf("internal server error (500): %s", resp.Status)
 		}
@@ -0,0 +1,221 @@
+// Package ratelimit provides the per-token adaptive throttling primitives
+// used by crawler.TokenManager: a token-bucket limiter bounding each
+// token's request rate, and a three-state circuit breaker (closed → open →
+// half-open) that takes a token out of rotation after it gets rate-limited
+// and lets it back in gradually rather than all at once.
+//
+// Everything here is plain data plus pure functions rather than a
+// self-locking type: callers already serialize access to a token's state
+// behind LinkedInCrawler.TokenMutex (see TokenState's doc comment), so
+// adding a second lock here would just be redundant.
+package ratelimit
+
+import (
+	"math/rand"
+	"time"
+)
+
+// State is a circuit breaker state for a single token.
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+const (
+	// breakerCooldownBase is the cooldown applied the first time a token's
+	// breaker opens from a 429/999 response; it doubles on each further
+	// consecutive trip (see TripRateLimit) up to a cap, and halves back
+	// down on each success (see RecordSuccess). A halving that would drop
+	// it below breakerCooldownFloor resets it to zero instead, so the next
+	// trip starts over at breakerCooldownBase.
+	breakerCooldownBase  = 1 * time.Second
+	breakerCooldownFloor = 250 * time.Millisecond
+
+	// breakerCooldownDefaultCap is used when the caller's
+	// models.RateLimitConfig.BreakerCooldownCap is unset.
+	breakerCooldownDefaultCap = 10 * time.Minute
+
+	// serverErrorBase/Cap bound the decorrelated-jitter backoff applied
+	// after a 5xx response.
+	serverErrorBase = 1 * time.Second
+	serverErrorCap  = 30 * time.Second
+)
+
+// TokenState holds the token-bucket and circuit-breaker state for a single
+// token. Callers must hold the owning LinkedInCrawler's TokenMutex while
+// reading or mutating it, mirroring models.TokenState.
+type TokenState struct {
+	BucketTokens   float64
+	BucketLastFill time.Time
+
+	BreakerState       State
+	CooldownUntil      time.Time
+	PermanentlyInvalid bool
+
+	// CurrentCooldown is the breaker's cooldown duration as of the last
+	// trip, carried across trips so TripRateLimit can double it and
+	// RecordSuccess can halve it rather than recomputing from scratch.
+	CurrentCooldown time.Duration
+
+	// LastServerErrorBackoff is the previous 5xx backoff duration, used as
+	// the decorrelated-jitter seed for the next one.
+	LastServerErrorBackoff time.Duration
+}
+
+// NewTokenState returns a fresh TokenState with a full bucket.
+func NewTokenState(burst float64) *TokenState {
+	return &TokenState{BucketTokens: burst, BucketLastFill: time.Now()}
+}
+
+// refill tops up the bucket for elapsed time at rate tokens/sec, capped at
+// burst.
+func refill(s *TokenState, rate, burst float64) {
+	now := time.Now()
+	if elapsed := now.Sub(s.BucketLastFill).Seconds(); elapsed > 0 {
+		s.BucketTokens += elapsed * rate
+		if s.BucketTokens > burst {
+			s.BucketTokens = burst
+		}
+		s.BucketLastFill = now
+	}
+}
+
+// CanAttempt reports whether s currently allows a request: it isn't
+// permanently invalid, its breaker isn't open (moving it to half-open once
+// the cooldown has elapsed), and its bucket has capacity. It does not
+// consume a bucket token - call Consume once a candidate is actually
+// selected, so peeking at several tokens during selection doesn't drain
+// the ones that weren't picked.
+func CanAttempt(s *TokenState, rate, burst float64) bool {
+	if s.PermanentlyInvalid {
+		return false
+	}
+
+	if s.BreakerState == Open {
+		if time.Now().Before(s.CooldownUntil) {
+			return false
+		}
+		// Cooldown elapsed: let a single probe through to test the token
+		// before trusting it with full traffic again.
+		s.BreakerState = HalfOpen
+	}
+
+	refill(s, rate, burst)
+	return s.BucketTokens >= 1
+}
+
+// Consume deducts one request from the bucket.
+func Consume(s *TokenState) {
+	if s.BucketTokens >= 1 {
+		s.BucketTokens--
+	}
+}
+
+// RecordSuccess closes the breaker (including out of half-open) and halves
+// its cooldown duration so a token that's recovering regains full trust
+// gradually instead of snapping straight back to a zero backoff after one
+// lucky probe. A halving that would take the cooldown below
+// breakerCooldownFloor resets it to zero.
+func RecordSuccess(s *TokenState) {
+	s.BreakerState = Closed
+	s.CooldownUntil = time.Time{}
+	s.LastServerErrorBackoff = 0
+
+	if s.CurrentCooldown > 0 {
+		s.CurrentCooldown /= 2
+		if s.CurrentCooldown < breakerCooldownFloor {
+			s.CurrentCooldown = 0
+		}
+	}
+}
+
+// TripRateLimit opens the breaker after a 429/999 response for
+// s.CurrentCooldown, which starts at breakerCooldownBase and doubles on
+// each consecutive trip up to cap (breakerCooldownDefaultCap if cap <= 0).
+// A half-open probe that gets rate-limited again counts as another
+// consecutive trip, growing the cooldown further.
+func TripRateLimit(s *TokenState, maxCooldown time.Duration) {
+	if maxCooldown <= 0 {
+		maxCooldown = breakerCooldownDefaultCap
+	}
+
+	if s.CurrentCooldown <= 0 {
+		s.CurrentCooldown = breakerCooldownBase
+	} else {
+		s.CurrentCooldown *= 2
+	}
+	if s.CurrentCooldown > maxCooldown {
+		s.CurrentCooldown = maxCooldown
+	}
+
+	s.BreakerState = Open
+	s.CooldownUntil = time.Now().Add(s.CurrentCooldown)
+}
+
+// MarkInvalid permanently disables the token (401/403: the credential
+// itself is bad, not just rate-limited) so it's never selected again.
+func MarkInvalid(s *TokenState) {
+	s.PermanentlyInvalid = true
+	s.BreakerState = Open
+}
+
+// ServerErrorBackoff returns how long to wait before this token's next
+// attempt after a 5xx, using decorrelated jitter
+// (sleep = min(cap, rand(base, prev*3))) so repeated 5xx responses across
+// many tokens don't all retry in lockstep. The result is remembered on s as
+// the seed for the next call, and is meant to be applied as a
+// CooldownUntil on that token alone - other tokens keep working
+// unaffected.
+func ServerErrorBackoff(s *TokenState) time.Duration {
+	prev := s.LastServerErrorBackoff
+	if prev <= 0 {
+		prev = serverErrorBase
+	}
+
+	upper := prev * 3
+	if upper < serverErrorBase {
+		upper = serverErrorBase
+	}
+
+	delay := serverErrorBase
+	if span := int64(upper - serverErrorBase); span > 0 {
+		delay += time.Duration(rand.Int63n(span))
+	}
+	if delay > serverErrorCap {
+		delay = serverErrorCap
+	}
+
+	s.LastServerErrorBackoff = delay
+	return delay
+}
+
+// Stats is a read-only snapshot of a token's rate-limit/circuit-breaker
+// state, for display in the progress reporter or export on /metrics.
+type Stats struct {
+	State        string
+	BackoffUntil time.Time
+	Invalid      bool
+}
+
+// StatsOf snapshots s.
+func StatsOf(s *TokenState) Stats {
+	return Stats{
+		State:        s.BreakerState.String(),
+		BackoffUntil: s.CooldownUntil,
+		Invalid:      s.PermanentlyInvalid,
+	}
+}
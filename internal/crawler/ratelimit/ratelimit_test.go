@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCanAttemptRespectsCooldown(t *testing.T) {
+	s := NewTokenState(3)
+
+	TripRateLimit(s, 0)
+	if CanAttempt(s, 1, 3) {
+		t.Fatalf("token should not be usable while its breaker is open")
+	}
+
+	s.CooldownUntil = time.Now().Add(-time.Second)
+	if !CanAttempt(s, 1, 3) {
+		t.Fatalf("token should be usable again once its cooldown has elapsed")
+	}
+	if s.BreakerState != HalfOpen {
+		t.Fatalf("an elapsed cooldown should move the breaker to half-open, got %v", s.BreakerState)
+	}
+}
+
+func TestTripRateLimitDoublesUpToCap(t *testing.T) {
+	s := NewTokenState(3)
+	maxCooldown := 4 * time.Second
+
+	TripRateLimit(s, maxCooldown)
+	if s.CurrentCooldown != breakerCooldownBase {
+		t.Fatalf("first trip should start at %v, got %v", breakerCooldownBase, s.CurrentCooldown)
+	}
+
+	TripRateLimit(s, maxCooldown)
+	if s.CurrentCooldown != 2*breakerCooldownBase {
+		t.Fatalf("second trip should double to %v, got %v", 2*breakerCooldownBase, s.CurrentCooldown)
+	}
+
+	TripRateLimit(s, maxCooldown)
+	TripRateLimit(s, maxCooldown)
+	if s.CurrentCooldown != maxCooldown {
+		t.Fatalf("cooldown should be capped at %v, got %v", maxCooldown, s.CurrentCooldown)
+	}
+}
+
+func TestRecordSuccessHalvesCooldownWithFloor(t *testing.T) {
+	s := NewTokenState(3)
+	s.CurrentCooldown = 1 * time.Second
+
+	RecordSuccess(s)
+	if s.CurrentCooldown != 500*time.Millisecond {
+		t.Fatalf("success should halve the cooldown, got %v", s.CurrentCooldown)
+	}
+	if s.BreakerState != Closed {
+		t.Fatalf("success should close the breaker, got %v", s.BreakerState)
+	}
+
+	RecordSuccess(s)
+	if s.CurrentCooldown != breakerCooldownFloor {
+		t.Fatalf("halving to exactly the floor should stick at %v, got %v", breakerCooldownFloor, s.CurrentCooldown)
+	}
+
+	RecordSuccess(s)
+	if s.CurrentCooldown != 0 {
+		t.Fatalf("halving below the floor should reset to 0, got %v", s.CurrentCooldown)
+	}
+}
+
+func TestMarkInvalidIsPermanent(t *testing.T) {
+	s := NewTokenState(3)
+	MarkInvalid(s)
+
+	if CanAttempt(s, 1, 3) {
+		t.Fatalf("a permanently invalid token should never pass CanAttempt")
+	}
+	RecordSuccess(s)
+	if !s.PermanentlyInvalid {
+		t.Fatalf("RecordSuccess must not clear PermanentlyInvalid")
+	}
+}
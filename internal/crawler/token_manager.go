@@ -7,37 +7,294 @@ import (
 	"sync"
 	"time"
 
+	"linkedin-crawler/internal/crawler/ratelimit"
+	"linkedin-crawler/internal/logging"
+	"linkedin-crawler/internal/metrics"
 	"linkedin-crawler/internal/models"
 	"linkedin-crawler/internal/storage"
+	"linkedin-crawler/internal/utils"
 )
 
-// TokenManager handles token rotation and validation
+func init() {
+	// Seed once at process start rather than creating a new *rand.Rand per
+	// call; the package-level source is safe for concurrent use.
+	rand.Seed(time.Now().UnixNano())
+}
+
+// TokenManager handles token rotation and validation. Rate limiting and
+// circuit breaking per token live in crawler/ratelimit; TokenManager wires
+// that state into LinkedInCrawler and layers latency-weighted selection on
+// top of it.
 type TokenManager struct {
-	mutex sync.Mutex
+	mutex      sync.Mutex
+	rate       float64
+	burst      float64
+	breakerCap time.Duration
+}
+
+// NewTokenManager creates a TokenManager whose per-token bucket refills at
+// rl.RatePerSecond up to rl.Burst, and whose breaker cooldown grows no
+// larger than rl.BreakerCooldownCap (ratelimit's own default if unset).
+func NewTokenManager(rl models.RateLimitConfig) *TokenManager {
+	return &TokenManager{rate: rl.RatePerSecond, burst: rl.Burst, breakerCap: rl.BreakerCooldownCap}
+}
+
+// stats returns (creating if necessary) the TokenState for token. Callers
+// must hold lc.TokenMutex.
+func stats(lc *models.LinkedInCrawler, token string) *models.TokenState {
+	if lc.TokenStats == nil {
+		lc.TokenStats = make(map[string]*models.TokenState)
+	}
+	s, ok := lc.TokenStats[token]
+	if !ok {
+		s = &models.TokenState{SuccessEWMA: 1}
+		lc.TokenStats[token] = s
+	}
+	return s
+}
+
+// rlStats returns (creating if necessary) the ratelimit.TokenState for
+// token. Callers must hold lc.TokenMutex.
+func rlStats(lc *models.LinkedInCrawler, token string, burst float64) *ratelimit.TokenState {
+	if lc.RateLimitStats == nil {
+		lc.RateLimitStats = make(map[string]*ratelimit.TokenState)
+	}
+	s, ok := lc.RateLimitStats[token]
+	if !ok {
+		s = ratelimit.NewTokenState(burst)
+		lc.RateLimitStats[token] = s
+	}
+	return s
+}
+
+type tokenCandidate struct {
+	token  string
+	weight float64
+}
+
+// weightedCandidates returns, among tokens that aren't invalid (by file
+// removal or permanently by the breaker) and currently pass their
+// rate-limit/circuit-breaker check, a weighted-random candidate list
+// (weighted by success_ewma / (1 + avg_latency_ms/1000), same as before
+// ratelimit existed), plus the full set of non-invalid tokens as a
+// fallback. Callers must hold lc.TokenMutex.
+func (tm *TokenManager) weightedCandidates(lc *models.LinkedInCrawler) (candidates []tokenCandidate, fallback []string) {
+	for _, token := range lc.Tokens {
+		if lc.InvalidTokens[token] {
+			continue
+		}
+		rl := rlStats(lc, token, tm.burst)
+		if rl.PermanentlyInvalid {
+			continue
+		}
+		fallback = append(fallback, token)
+
+		if !ratelimit.CanAttempt(rl, tm.rate, tm.burst) {
+			continue
+		}
+
+		s := stats(lc, token)
+		weight := s.SuccessEWMA / (1 + float64(s.AvgLatencyMs)/1000)
+		if weight <= 0 {
+			weight = 0.01
+		}
+		candidates = append(candidates, tokenCandidate{token: token, weight: weight})
+	}
+	return candidates, fallback
 }
 
-// GetToken returns a random valid token
+func pickWeighted(candidates []tokenCandidate) string {
+	total := 0.0
+	for _, c := range candidates {
+		total += c.weight
+	}
+
+	pick := rand.Float64() * total
+	for _, c := range candidates {
+		pick -= c.weight
+		if pick <= 0 {
+			return c.token
+		}
+	}
+	return candidates[len(candidates)-1].token
+}
+
+// GetToken returns a token drawn from the valid pool, weighted by recent
+// success rate and latency. If every token is currently rate-limited or
+// cooling down, it falls back to any non-invalid token rather than
+// returning nothing, so a temporary breaker window can't stall the whole
+// pool outright; callers that can tolerate waiting for a properly-paced
+// token instead should use Acquire.
 func (tm *TokenManager) GetToken(lc *models.LinkedInCrawler) string {
 	lc.TokenMutex.Lock()
 	defer lc.TokenMutex.Unlock()
 
-	validTokens := []string{}
+	candidates, fallback := tm.weightedCandidates(lc)
+
+	if len(candidates) == 0 {
+		if len(fallback) > 0 {
+			return fallback[rand.Intn(len(fallback))]
+		}
+		if len(lc.Tokens) > 0 {
+			return lc.Tokens[0]
+		}
+		return ""
+	}
+
+	chosen := pickWeighted(candidates)
+	ratelimit.Consume(rlStats(lc, chosen, tm.burst))
+	return chosen
+}
+
+// Acquire blocks until a token passes its rate limiter and circuit breaker
+// (unlike GetToken, it never falls back to a token still in cooldown), or
+// ctx is done, or every token is permanently unusable. If every token is
+// merely cooling down rather than permanently dead, it blocks until the
+// earliest CooldownUntil instead of failing.
+func (tm *TokenManager) Acquire(ctx context.Context, lc *models.LinkedInCrawler) (string, error) {
+	for {
+		if token := tm.tryAcquire(lc); token != "" {
+			return token, nil
+		}
+		if tm.AreAllTokensFailed(lc) || tm.allTokensPermanentlyInvalid(lc) {
+			return "", fmt.Errorf("all tokens have failed")
+		}
+
+		wait := 100 * time.Millisecond
+		if until := tm.earliestCooldown(lc); !until.IsZero() {
+			if remaining := time.Until(until); remaining > wait {
+				wait = remaining
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// allTokensPermanentlyInvalid reports whether every token is unusable for
+// the rest of the run (file-removed or permanently invalidated by the
+// breaker), ignoring tokens that are merely cooling down - those are
+// expected to become usable again, so Acquire should wait for them rather
+// than give up.
+func (tm *TokenManager) allTokensPermanentlyInvalid(lc *models.LinkedInCrawler) bool {
+	lc.TokenMutex.Lock()
+	defer lc.TokenMutex.Unlock()
+
+	if len(lc.Tokens) == 0 {
+		return true
+	}
 	for _, token := range lc.Tokens {
-		if !lc.InvalidTokens[token] {
-			validTokens = append(validTokens, token)
+		if lc.InvalidTokens[token] {
+			continue
+		}
+		if rlStats(lc, token, tm.burst).PermanentlyInvalid {
+			continue
 		}
+		return false
 	}
+	return true
+}
 
-	if len(validTokens) == 0 {
-		if len(lc.Tokens) > 0 {
-			return lc.Tokens[0]
+// earliestCooldown returns the soonest CooldownUntil among tokens whose
+// breaker is currently open, or the zero Time if none is open (e.g. every
+// token is merely rate-limited by its bucket, which refills quickly).
+func (tm *TokenManager) earliestCooldown(lc *models.LinkedInCrawler) time.Time {
+	lc.TokenMutex.Lock()
+	defer lc.TokenMutex.Unlock()
+
+	var earliest time.Time
+	for _, token := range lc.Tokens {
+		rl := rlStats(lc, token, tm.burst)
+		if rl.BreakerState != ratelimit.Open {
+			continue
+		}
+		if earliest.IsZero() || rl.CooldownUntil.Before(earliest) {
+			earliest = rl.CooldownUntil
 		}
+	}
+	return earliest
+}
+
+// tryAcquire returns a weighted-random token that currently passes its
+// rate limiter and circuit breaker, or "" if none do.
+func (tm *TokenManager) tryAcquire(lc *models.LinkedInCrawler) string {
+	lc.TokenMutex.Lock()
+	defer lc.TokenMutex.Unlock()
+
+	candidates, _ := tm.weightedCandidates(lc)
+	if len(candidates) == 0 {
 		return ""
 	}
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	idx := r.Intn(len(validTokens))
-	return validTokens[idx]
+	chosen := pickWeighted(candidates)
+	ratelimit.Consume(rlStats(lc, chosen, tm.burst))
+	return chosen
+}
+
+// RecordSuccess updates a token's EWMA success rate and average latency
+// after a successful request, and closes its circuit breaker.
+func (tm *TokenManager) RecordSuccess(lc *models.LinkedInCrawler, token string, latency time.Duration) {
+	lc.TokenMutex.Lock()
+	defer lc.TokenMutex.Unlock()
+
+	s := stats(lc, token)
+	const alpha = 0.2
+	s.SuccessEWMA = alpha*1 + (1-alpha)*s.SuccessEWMA
+	latencyMs := latency.Milliseconds()
+	if s.AvgLatencyMs == 0 {
+		s.AvgLatencyMs = latencyMs
+	} else {
+		s.AvgLatencyMs = int64(alpha*float64(latencyMs) + (1-alpha)*float64(s.AvgLatencyMs))
+	}
+	s.ConsecutiveFailures = 0
+	s.CooldownUntil = time.Time{}
+
+	ratelimit.RecordSuccess(rlStats(lc, token, tm.burst))
+}
+
+// RecordRateLimit opens the token's circuit breaker for an
+// exponentially-growing cooldown after it hits HTTP 429/999.
+func (tm *TokenManager) RecordRateLimit(lc *models.LinkedInCrawler, token string) {
+	lc.TokenMutex.Lock()
+	defer lc.TokenMutex.Unlock()
+
+	s := stats(lc, token)
+	s.ConsecutiveFailures++
+	s.SuccessEWMA *= 0.5
+
+	rl := rlStats(lc, token, tm.burst)
+	ratelimit.TripRateLimit(rl, tm.breakerCap)
+	s.CooldownUntil = rl.CooldownUntil
+
+	metrics.RateLimitHitsTotal.Inc(utils.TokenFingerprint(token))
+}
+
+// RecordServerError applies a decorrelated-jitter backoff
+// (sleep = min(cap, rand(base, prev*3))) to token alone after a 5xx
+// response, which is usually transient and shouldn't cost it the breaker's
+// long rate-limit cooldown. Other tokens are unaffected and keep working.
+func (tm *TokenManager) RecordServerError(lc *models.LinkedInCrawler, token string) {
+	lc.TokenMutex.Lock()
+	defer lc.TokenMutex.Unlock()
+
+	s := stats(lc, token)
+	s.ConsecutiveFailures++
+
+	rl := rlStats(lc, token, tm.burst)
+	backoff := ratelimit.ServerErrorBackoff(rl)
+	s.CooldownUntil = time.Now().Add(backoff)
+	rl.CooldownUntil = s.CooldownUntil
+}
+
+// Stats returns a snapshot of token's rate-limit/circuit-breaker state for
+// display (progress reporter) or export (/metrics).
+func (tm *TokenManager) Stats(lc *models.LinkedInCrawler, token string) ratelimit.Stats {
+	lc.TokenMutex.Lock()
+	defer lc.TokenMutex.Unlock()
+	return ratelimit.StatsOf(rlStats(lc, token, tm.burst))
 }
 
 // AreAllTokensFailed checks if all tokens have failed
@@ -47,11 +304,14 @@ func (tm *TokenManager) AreAllTokensFailed(lc *models.LinkedInCrawler) bool {
 	return lc.AllTokensFailed
 }
 
-// MarkTokenAsInvalid marks a token as invalid
+// MarkTokenAsInvalid marks a token as invalid, permanently (401/403): it is
+// removed from rotation for the rest of the run and never re-enters via the
+// breaker's half-open retry.
 func (tm *TokenManager) MarkTokenAsInvalid(lc *models.LinkedInCrawler, token string) {
 	lc.TokenMutex.Lock()
 	defer lc.TokenMutex.Unlock()
 	lc.InvalidTokens[token] = true
+	ratelimit.MarkInvalid(rlStats(lc, token, tm.burst))
 }
 
 // SetAllTokensFailed sets the flag indicating all tokens have failed
@@ -75,19 +335,31 @@ func (tm *TokenManager) GetValidTokenCount(lc *models.LinkedInCrawler) int {
 	return validCount
 }
 
-// CheckIfAllTokensInvalid checks if all tokens are now invalid and updates the flag
+// CheckIfAllTokensInvalid checks whether every token is now unusable — file
+// invalid, permanently invalidated by the breaker, or still within an open
+// breaker's cooldown window — and updates AllTokensFailed accordingly.
 func (tm *TokenManager) CheckIfAllTokensInvalid(lc *models.LinkedInCrawler) bool {
 	lc.TokenMutex.Lock()
 	defer lc.TokenMutex.Unlock()
 
-	invalidCount := 0
+	now := time.Now()
+	downCount := 0
 	for _, token := range lc.Tokens {
 		if lc.InvalidTokens[token] {
-			invalidCount++
+			downCount++
+			continue
+		}
+		rl := rlStats(lc, token, tm.burst)
+		if rl.PermanentlyInvalid {
+			downCount++
+			continue
+		}
+		if rl.BreakerState == ratelimit.Open && now.Before(rl.CooldownUntil) {
+			downCount++
 		}
 	}
 
-	if invalidCount >= len(lc.Tokens) {
+	if downCount >= len(lc.Tokens) {
 		lc.AllTokensFailed = true
 		return true
 	}
@@ -131,7 +403,7 @@ func (vs *ValidatorService) HasValidTokens(config models.Config, outputFile stri
 	validCount := 0
 	checkLimit := 3 // Only check first 3 tokens to save time
 
-	queryService := NewQueryService()
+	queryService := NewQueryService(config.TokenRateLimit)
 
 	for i, token := range existingTokens {
 		if i >= checkLimit {
@@ -173,12 +445,12 @@ func (vs *ValidatorService) ValidateExistingTokens(tokens []string, config model
 		testEmail = totalEmails[0]
 	}
 
-	fmt.Printf("🔍 Kiểm tra %d tokens với email test: %s\n", len(tokens), testEmail)
+	logging.Logger.Info("🔍 Kiểm tra tokens", "count", len(tokens), "test_email", testEmail)
 
-	queryService := NewQueryService()
+	queryService := NewQueryService(config.TokenRateLimit)
 
 	for i, token := range tokens {
-		fmt.Printf("  🔑 Kiểm tra token %d/%d...\n", i+1, len(tokens))
+		tokenLog := logging.WithToken(token)
 
 		ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
 		_, _, statusCode, err := queryService.DoQueryProfile(tempCrawler, ctx, testEmail, token)
@@ -186,15 +458,15 @@ func (vs *ValidatorService) ValidateExistingTokens(tokens []string, config model
 
 		if err == nil || statusCode == 429 || statusCode == 500 {
 			validTokens = append(validTokens, token)
-			fmt.Printf("  ✅ Token %d hợp lệ (status: %d)\n", i+1, statusCode)
+			tokenLog.Info("✅ Token hợp lệ", "index", i+1, "total", len(tokens), "status", statusCode)
 		} else {
-			fmt.Printf("  ❌ Token %d không hợp lệ (status: %d, error: %v)\n", i+1, statusCode, err)
+			tokenLog.Warn("❌ Token không hợp lệ", "index", i+1, "total", len(tokens), "status", statusCode, "error", err)
 			// Only remove token when 401 or 424, NOT when 500
 			if statusCode == 401 || statusCode == 424 {
 				if err := vs.tokenStorage.RemoveTokenFromFile(config.TokensFilePath, token); err != nil {
-					fmt.Printf("  ⚠️ Không thể xóa token khỏi file: %v\n", err)
+					tokenLog.Error("⚠️ Không thể xóa token khỏi file", "error", err)
 				} else {
-					fmt.Printf("  🗑️ Đã xóa token không hợp lệ khỏi file\n")
+					tokenLog.Info("🗑️ Đã xóa token không hợp lệ khỏi file")
 				}
 			}
 		}
@@ -202,7 +474,7 @@ func (vs *ValidatorService) ValidateExistingTokens(tokens []string, config model
 		time.Sleep(1 * time.Second)
 	}
 
-	fmt.Printf("✅ Kết quả kiểm tra: %d/%d tokens hợp lệ\n", len(validTokens), len(tokens))
+	logging.Logger.Info("✅ Kết quả kiểm tra token", "valid", len(validTokens), "total", len(tokens))
 	return validTokens, nil
 }
 
@@ -228,10 +500,10 @@ func (vs *ValidatorService) ValidateTokensBatch(tokens []string, config models.C
 		testEmail = totalEmails[0]
 	}
 
-	queryService := NewQueryService()
+	queryService := NewQueryService(config.TokenRateLimit)
 
 	for i, token := range tokens {
-		fmt.Printf("    🔑 Kiểm tra token %d/%d...\n", i+1, len(tokens))
+		tokenLog := logging.WithToken(token)
 
 		ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
 		_, _, statusCode, err := queryService.DoQueryProfile(tempCrawler, ctx, testEmail, token)
@@ -239,9 +511,9 @@ func (vs *ValidatorService) ValidateTokensBatch(tokens []string, config models.C
 
 		if err == nil || statusCode == 429 || statusCode == 500 {
 			validTokens = append(validTokens, token)
-			fmt.Printf("    ✅ Token %d hợp lệ (status: %d)\n", i+1, statusCode)
+			tokenLog.Info("✅ Token hợp lệ", "index", i+1, "total", len(tokens), "status", statusCode)
 		} else {
-			fmt.Printf("    ❌ Token %d không hợp lệ (status: %d, error: %v) - Bỏ qua\n", i+1, statusCode, err)
+			tokenLog.Warn("❌ Token không hợp lệ, bỏ qua", "index", i+1, "total", len(tokens), "status", statusCode, "error", err)
 		}
 
 		time.Sleep(1 * time.Second)
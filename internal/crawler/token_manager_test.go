@@ -0,0 +1,57 @@
+package crawler
+
+import (
+	"testing"
+
+	"linkedin-crawler/internal/models"
+)
+
+func TestPickWeightedSingleCandidate(t *testing.T) {
+	candidates := []tokenCandidate{{token: "low", weight: 0.01}}
+	if got := pickWeighted(candidates); got != "low" {
+		t.Fatalf("single candidate should always be picked, got %q", got)
+	}
+
+	candidates = []tokenCandidate{{token: "only", weight: 5}}
+	if got := pickWeighted(candidates); got != "only" {
+		t.Fatalf("single candidate should always be picked, got %q", got)
+	}
+}
+
+func TestWeightedCandidatesSkipsInvalidAndRateLimited(t *testing.T) {
+	tm := NewTokenManager(models.RateLimitConfig{RatePerSecond: 1, Burst: 1})
+	lc := &models.LinkedInCrawler{
+		Tokens:        []string{"good", "invalid", "limited"},
+		InvalidTokens: map[string]bool{"invalid": true},
+	}
+
+	// Exhaust "limited"'s bucket so it fails its rate-limit check.
+	rl := rlStats(lc, "limited", tm.burst)
+	rl.BucketTokens = 0
+
+	candidates, fallback := tm.weightedCandidates(lc)
+
+	if len(candidates) != 1 || candidates[0].token != "good" {
+		t.Fatalf("expected only \"good\" as a candidate, got %+v", candidates)
+	}
+	if len(fallback) != 2 {
+		t.Fatalf("fallback should include every non-invalid token, got %v", fallback)
+	}
+	for _, token := range fallback {
+		if token == "invalid" {
+			t.Fatalf("fallback must not include invalidated tokens, got %v", fallback)
+		}
+	}
+}
+
+func TestWeightedCandidatesSkipsPermanentlyInvalid(t *testing.T) {
+	tm := NewTokenManager(models.RateLimitConfig{RatePerSecond: 1, Burst: 1})
+	lc := &models.LinkedInCrawler{Tokens: []string{"dead"}}
+
+	rlStats(lc, "dead", tm.burst).PermanentlyInvalid = true
+
+	candidates, fallback := tm.weightedCandidates(lc)
+	if len(candidates) != 0 || len(fallback) != 0 {
+		t.Fatalf("a permanently invalid token must not appear as a candidate or fallback, got candidates=%+v fallback=%v", candidates, fallback)
+	}
+}
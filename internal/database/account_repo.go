@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -19,18 +20,18 @@ func NewAccountRepository(db *DB) *AccountRepository {
 }
 
 // ImportAccounts imports accounts from a list
-func (ar *AccountRepository) ImportAccounts(accounts []models.Account) error {
+func (ar *AccountRepository) ImportAccounts(ctx context.Context, accounts []models.Account) error {
 	if len(accounts) == 0 {
 		return nil
 	}
 
-	tx, err := ar.db.Begin()
+	tx, err := ar.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`
+	stmt, err := tx.PrepareContext(ctx, `
 		INSERT OR IGNORE INTO accounts (email, password) VALUES (?, ?)
 	`)
 	if err != nil {
@@ -39,7 +40,7 @@ func (ar *AccountRepository) ImportAccounts(accounts []models.Account) error {
 	defer stmt.Close()
 
 	for _, account := range accounts {
-		if _, err := stmt.Exec(account.Email, account.Password); err != nil {
+		if _, err := stmt.ExecContext(ctx, account.Email, account.Password); err != nil {
 			return fmt.Errorf("failed to insert account %s: %w", account.Email, err)
 		}
 	}
@@ -48,13 +49,13 @@ func (ar *AccountRepository) ImportAccounts(accounts []models.Account) error {
 }
 
 // GetUnusedAccounts returns accounts that haven't been used yet
-func (ar *AccountRepository) GetUnusedAccounts(limit int) ([]models.Account, error) {
+func (ar *AccountRepository) GetUnusedAccounts(ctx context.Context, limit int) ([]models.Account, error) {
 	query := `SELECT email, password FROM accounts WHERE is_used = 0 ORDER BY id`
 	if limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
 
-	rows, err := ar.db.Query(query)
+	rows, err := ar.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -73,20 +74,20 @@ func (ar *AccountRepository) GetUnusedAccounts(limit int) ([]models.Account, err
 }
 
 // MarkAccountAsUsed marks an account as used
-func (ar *AccountRepository) MarkAccountAsUsed(email string) error {
-	_, err := ar.db.Exec(`
-		UPDATE accounts 
-		SET is_used = 1, 
-			updated_at = CURRENT_TIMESTAMP 
+func (ar *AccountRepository) MarkAccountAsUsed(ctx context.Context, email string) error {
+	_, err := ar.db.ExecContext(ctx, `
+		UPDATE accounts
+		SET is_used = 1,
+			updated_at = CURRENT_TIMESTAMP
 		WHERE email = ?
 	`, email)
 	return err
 }
 
 // GetUnusedAccountCount returns the count of unused accounts
-func (ar *AccountRepository) GetUnusedAccountCount() (int, error) {
+func (ar *AccountRepository) GetUnusedAccountCount(ctx context.Context) (int, error) {
 	var count int
-	err := ar.db.QueryRow(`
+	err := ar.db.QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM accounts WHERE is_used = 0
 	`).Scan(&count)
 	return count, err
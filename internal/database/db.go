@@ -15,6 +15,26 @@ type DB struct {
 
 // New creates a new database connection
 func New(dbPath string) (*DB, error) {
+	db, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply pending schema migrations. Unlike the old InitSchema, this never
+	// drops emails/tokens/accounts, so progress survives a restart.
+	if err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	return db, nil
+}
+
+// Open connects to dbPath without applying any pending migrations, for
+// tooling (the `migrate status`/`migrate down` CLI subcommands) that needs
+// to inspect or roll back schema state without New's Migrate call refusing
+// to proceed against a schema newer than this binary knows.
+func Open(dbPath string) (*DB, error) {
 	// Add connection parameters for better performance
 	conn, err := sql.Open("sqlite3", fmt.Sprintf("%s?_journal_mode=WAL&_synchronous=NORMAL&_cache_size=10000&_timeout=5000", dbPath))
 	if err != nil {
@@ -26,79 +46,24 @@ func New(dbPath string) (*DB, error) {
 	conn.SetMaxIdleConns(1)
 	conn.SetConnMaxLifetime(time.Hour)
 
-	db := &DB{conn: conn}
-
-	// Initialize schema
-	if err := db.InitSchema(); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
-	}
-
-	return db, nil
+	return &DB{conn: conn}, nil
 }
 
-// InitSchema creates all necessary tables (drops existing data)
-func (db *DB) InitSchema() error {
-	// Drop existing tables
-	dropQueries := []string{
-		`DROP TABLE IF EXISTS emails`,
-		`DROP TABLE IF EXISTS tokens`,
-		`DROP TABLE IF EXISTS accounts`,
-	}
-
-	for _, query := range dropQueries {
-		if _, err := db.conn.Exec(query); err != nil {
-			return fmt.Errorf("failed to drop table: %w", err)
-		}
-	}
-
-	// Create new tables
-	createQueries := []string{
-		`CREATE TABLE emails (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			email TEXT UNIQUE NOT NULL,
-			status TEXT NOT NULL DEFAULT 'pending',
-			profile_user TEXT,
-			profile_url TEXT,
-			profile_location TEXT,
-			profile_connections TEXT,
-			retry_count INTEGER DEFAULT 0,
-			last_error TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX idx_emails_status ON emails(status)`,
-		`CREATE INDEX idx_emails_email ON emails(email)`,
-
-		`CREATE TABLE tokens (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			token TEXT UNIQUE NOT NULL,
-			is_valid BOOLEAN DEFAULT 1,
-			failure_count INTEGER DEFAULT 0,
-			last_used_at TIMESTAMP,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX idx_tokens_is_valid ON tokens(is_valid)`,
-
-		`CREATE TABLE accounts (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			email TEXT UNIQUE NOT NULL,
-			password TEXT NOT NULL,
-			is_used BOOLEAN DEFAULT 0,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX idx_accounts_is_used ON accounts(is_used)`,
+// NewWithReset is like New but wipes the schema back to empty before
+// migrating, for the rare case (--reset) a user actually wants a clean
+// slate instead of resuming crawled progress.
+func NewWithReset(dbPath string) (*DB, error) {
+	db, err := New(dbPath)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, query := range createQueries {
-		if _, err := db.conn.Exec(query); err != nil {
-			return fmt.Errorf("failed to create table: %w", err)
-		}
+	if err := db.Reset(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to reset schema: %w", err)
 	}
 
-	return nil
+	return db, nil
 }
 
 // Close closes the database connection
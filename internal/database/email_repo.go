@@ -1,10 +1,13 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
+	"linkedin-crawler/internal/metrics"
 	"linkedin-crawler/internal/models"
 )
 
@@ -86,6 +89,84 @@ func (er *EmailRepository) GetPendingEmails(limit int) ([]string, error) {
 	return emails, rows.Err()
 }
 
+// EmailRecord is a full row from the emails table, used by callers (the
+// control-plane API) that need more than just the email string.
+type EmailRecord struct {
+	ID                 int64
+	Email              string
+	Status             EmailStatus
+	ProfileUser        string
+	ProfileURL         string
+	ProfileLocation    string
+	ProfileConnections string
+	RetryCount         int
+	LastError          string
+}
+
+func scanEmailRecord(row interface {
+	Scan(dest ...interface{}) error
+}) (EmailRecord, error) {
+	var r EmailRecord
+	var status string
+	var profileUser, profileURL, profileLocation, profileConnections, lastError sql.NullString
+	err := row.Scan(&r.ID, &r.Email, &status, &profileUser, &profileURL, &profileLocation,
+		&profileConnections, &r.RetryCount, &lastError)
+	r.Status = EmailStatus(status)
+	r.ProfileUser = profileUser.String
+	r.ProfileURL = profileURL.String
+	r.ProfileLocation = profileLocation.String
+	r.ProfileConnections = profileConnections.String
+	r.LastError = lastError.String
+	return r, err
+}
+
+// GetEmail returns the full record for a single email.
+func (er *EmailRepository) GetEmail(email string) (EmailRecord, error) {
+	row := er.db.QueryRow(`
+		SELECT id, email, status, profile_user, profile_url, profile_location, profile_connections, retry_count, last_error
+		FROM emails WHERE email = ?
+	`, email)
+	return scanEmailRecord(row)
+}
+
+// GetEmailPage returns up to pageSize emails with id > afterID, optionally
+// filtered to a single status (empty status means any), ordered by id so
+// afterID (the last row's ID from the previous page) is a stable
+// pagination cursor across pages - the same page_token/page_size shape the
+// control-plane API exposes over HTTP.
+func (er *EmailRepository) GetEmailPage(status EmailStatus, afterID int64, pageSize int) ([]EmailRecord, error) {
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+
+	query := `
+		SELECT id, email, status, profile_user, profile_url, profile_location, profile_connections, retry_count, last_error
+		FROM emails WHERE id > ?
+	`
+	args := []interface{}{afterID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += fmt.Sprintf(" ORDER BY id ASC LIMIT %d", pageSize)
+
+	rows, err := er.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []EmailRecord
+	for rows.Next() {
+		r, err := scanEmailRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
 // GetEmailsByStatus returns emails with specific status
 func (er *EmailRepository) GetEmailsByStatus(status EmailStatus) ([]string, error) {
 	rows, err := er.db.Query(`SELECT email FROM emails WHERE status = ?`, status)
@@ -106,32 +187,193 @@ func (er *EmailRepository) GetEmailsByStatus(status EmailStatus) ([]string, erro
 	return emails, rows.Err()
 }
 
-// UpdateEmailStatus updates the status of an email
+// UpdateEmailStatus updates the status of an email and releases its lease,
+// since a terminal (or retry-queued) status means no worker is holding it
+// in flight anymore
 func (er *EmailRepository) UpdateEmailStatus(email string, status EmailStatus) error {
 	_, err := er.db.Exec(`
-		UPDATE emails 
-		SET status = ?, updated_at = CURRENT_TIMESTAMP 
+		UPDATE emails
+		SET status = ?, attempt_id = NULL, leased_until = NULL, updated_at = CURRENT_TIMESTAMP
 		WHERE email = ?
 	`, status, email)
 	return err
 }
 
-// UpdateEmailWithProfile updates email with LinkedIn profile data
+// UpdateEmailWithProfile updates email with LinkedIn profile data and
+// releases its lease
 func (er *EmailRepository) UpdateEmailWithProfile(email string, profile models.ProfileData) error {
 	_, err := er.db.Exec(`
-		UPDATE emails 
-		SET status = ?, 
-			profile_user = ?, 
-			profile_url = ?, 
-			profile_location = ?, 
+		UPDATE emails
+		SET status = ?,
+			profile_user = ?,
+			profile_url = ?,
+			profile_location = ?,
 			profile_connections = ?,
-			updated_at = CURRENT_TIMESTAMP 
+			attempt_id = NULL,
+			leased_until = NULL,
+			updated_at = CURRENT_TIMESTAMP
 		WHERE email = ?
 	`, EmailStatusSuccessWithData, profile.User, profile.LinkedInURL,
 		profile.Location, profile.ConnectionCount, email)
 	return err
 }
 
+// LeaseEmails atomically claims up to limit pending/failed emails that
+// aren't already leased by another in-flight attempt, so multiple crawler
+// processes can cooperate on one DB without double-processing. A limit <= 0
+// means "all of them". Leases expire after leaseDuration;
+// ReclaimExpiredLeases returns abandoned ones (e.g. from a crashed worker)
+// to the pool.
+func (er *EmailRepository) LeaseEmails(attemptID string, limit int, leaseDuration time.Duration) ([]string, error) {
+	start := time.Now()
+	defer func() { metrics.DBQueryLatencySeconds.Observe(time.Since(start).Seconds()) }()
+
+	tx, err := er.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT email FROM emails
+		WHERE status IN ('pending', 'failed')
+		AND (leased_until IS NULL OR leased_until < CURRENT_TIMESTAMP)
+		ORDER BY retry_count ASC, id ASC
+	`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		candidates = append(candidates, email)
+	}
+	rows.Close()
+
+	if len(candidates) == 0 {
+		return nil, tx.Commit()
+	}
+
+	stmt, err := tx.Prepare(`
+		UPDATE emails
+		SET attempt_id = ?, leased_until = datetime(CURRENT_TIMESTAMP, ?)
+		WHERE email = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	offset := fmt.Sprintf("+%d seconds", int(leaseDuration.Seconds()))
+	for _, email := range candidates {
+		if _, err := stmt.Exec(attemptID, offset, email); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// ReleaseAttemptLeases returns every email currently leased by attemptID
+// back to the pool immediately, used on graceful shutdown so the next run
+// picks up in-flight emails right away instead of waiting for the lease to
+// expire.
+func (er *EmailRepository) ReleaseAttemptLeases(attemptID string) (int, error) {
+	result, err := er.db.Exec(`
+		UPDATE emails
+		SET attempt_id = NULL, leased_until = NULL
+		WHERE attempt_id = ?
+	`, attemptID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// ClaimShard atomically assigns up to n unclaimed pending/failed emails to
+// workerID, so a fleet of cooperating followers (see coordinator.LeaderElector)
+// can shard the workload by worker instead of every worker contending over
+// the same LeaseEmails pool. Unlike a lease, a claim doesn't expire on its
+// own - it's sticky until ReleaseShard hands it back, which a follower does
+// when it finishes its shard or loses its place in the fleet.
+func (er *EmailRepository) ClaimShard(ctx context.Context, workerID string, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	rows, err := er.db.QueryContext(ctx, `
+		UPDATE emails
+		SET claimed_by = ?
+		WHERE email IN (
+			SELECT email FROM emails
+			WHERE claimed_by IS NULL AND status IN ('pending', 'failed')
+			ORDER BY retry_count ASC, id ASC
+			LIMIT ?
+		)
+		RETURNING email
+	`, workerID, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim shard for %s: %w", workerID, err)
+	}
+	defer rows.Close()
+
+	var claimed []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, email)
+	}
+	return claimed, rows.Err()
+}
+
+// ReleaseShard clears every claim workerID holds, so those emails become
+// claimable by another worker immediately - used when a follower gives up
+// its shard (graceful shutdown, or losing the leader-election fleet).
+func (er *EmailRepository) ReleaseShard(ctx context.Context, workerID string) (int, error) {
+	result, err := er.db.ExecContext(ctx, `
+		UPDATE emails SET claimed_by = NULL WHERE claimed_by = ?
+	`, workerID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// ReclaimExpiredLeases clears leased_until/attempt_id on any email whose
+// lease has expired, so a stale lease left by a crashed worker is picked up
+// by the next LeaseEmails call rather than blocking it until it ages out
+// naturally.
+func (er *EmailRepository) ReclaimExpiredLeases() (int, error) {
+	result, err := er.db.Exec(`
+		UPDATE emails
+		SET attempt_id = NULL, leased_until = NULL
+		WHERE leased_until IS NOT NULL AND leased_until < CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
 // IncrementRetryCount increments the retry count for an email
 func (er *EmailRepository) IncrementRetryCount(email string, lastError string) error {
 	_, err := er.db.Exec(`
@@ -177,11 +419,13 @@ func (er *EmailRepository) GetEmailStats() (map[string]int, error) {
 	return stats, nil
 }
 
-// GetRemainingEmails returns emails that still need processing
+// GetRemainingEmails returns emails that still need processing and aren't
+// currently leased by another in-flight attempt
 func (er *EmailRepository) GetRemainingEmails() ([]string, error) {
 	rows, err := er.db.Query(`
-		SELECT email FROM emails 
-		WHERE status IN ('pending', 'failed') 
+		SELECT email FROM emails
+		WHERE status IN ('pending', 'failed')
+		AND (leased_until IS NULL OR leased_until < CURRENT_TIMESTAMP)
 		ORDER BY retry_count ASC, id ASC
 	`)
 	if err != nil {
@@ -201,12 +445,102 @@ func (er *EmailRepository) GetRemainingEmails() ([]string, error) {
 	return emails, rows.Err()
 }
 
-// CountRemainingEmails returns the count of emails that need processing
+// CountRemainingEmails returns the count of emails that need processing and
+// aren't currently leased by another in-flight attempt
 func (er *EmailRepository) CountRemainingEmails() (int, error) {
 	var count int
 	err := er.db.QueryRow(`
-		SELECT COUNT(*) FROM emails 
+		SELECT COUNT(*) FROM emails
 		WHERE status IN ('pending', 'failed')
+		AND (leased_until IS NULL OR leased_until < CURRENT_TIMESTAMP)
 	`).Scan(&count)
 	return count, err
 }
+
+// ArchivePermanentlyFailed moves permanent_failed emails last updated more
+// than retention ago into emails_archive, then deletes them from the live
+// table - keeping the working set small across a run that accumulates a
+// long tail of unreachable addresses. Returns the number archived.
+func (er *EmailRepository) ArchivePermanentlyFailed(retention time.Duration) (int, error) {
+	cutoff := time.Now().Add(-retention)
+
+	tx, err := er.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT OR IGNORE INTO emails_archive
+			(email, status, profile_user, profile_url, profile_location, profile_connections, retry_count, last_error, created_at, updated_at)
+		SELECT email, status, profile_user, profile_url, profile_location, profile_connections, retry_count, last_error, created_at, updated_at
+		FROM emails
+		WHERE status = ? AND updated_at < ?
+	`, EmailStatusPermanentFailed, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy emails into archive: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM emails WHERE status = ? AND updated_at < ?
+	`, EmailStatusPermanentFailed, cutoff); err != nil {
+		return 0, fmt.Errorf("failed to delete archived emails: %w", err)
+	}
+
+	return int(n), tx.Commit()
+}
+
+// GetPendingEmailsPrioritized returns up to limit pending emails ordered by
+// priority first (higher goes first), then by next_attempt_at (so an email
+// ScheduleRetry pushed into the future is skipped until its cooldown
+// elapses), then by retry_count (so emails with fewer retries are still
+// preferred over repeat offenders at the same priority/eligibility). As in
+// GetPendingEmails, limit <= 0 means "no limit" - callers that want the
+// original unprioritized id order, without the next_attempt_at filter,
+// should keep using GetPendingEmails.
+func (er *EmailRepository) GetPendingEmailsPrioritized(limit int) ([]string, error) {
+	query := `
+		SELECT email FROM emails
+		WHERE status = 'pending'
+		AND (next_attempt_at IS NULL OR next_attempt_at <= CURRENT_TIMESTAMP)
+		ORDER BY priority DESC, next_attempt_at ASC, retry_count ASC
+	`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := er.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+
+	return emails, rows.Err()
+}
+
+// ScheduleRetry pushes email's next_attempt_at delay into the future
+// without changing its status, so GetPendingEmailsPrioritized skips it
+// until the delay elapses - meant for a 429 that should be retried once
+// its token's adaptive cooldown has passed, surviving a restart in between
+// since it's persisted rather than kept in memory.
+func (er *EmailRepository) ScheduleRetry(email string, delay time.Duration) error {
+	_, err := er.db.Exec(`
+		UPDATE emails
+		SET next_attempt_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE email = ?
+	`, time.Now().Add(delay), email)
+	return err
+}
@@ -0,0 +1,276 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migration is a single numbered schema step loaded from migrations/.
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+// loadMigrations reads migrations/*.sql, pairing NNNN_name.up.sql with its
+// .down.sql counterpart, and returns them sorted by version.
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*migration)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		isUp := strings.HasSuffix(name, ".up.sql")
+		isDown := strings.HasSuffix(name, ".down.sql")
+		if !isUp && !isDown {
+			continue
+		}
+
+		base := strings.TrimSuffix(strings.TrimSuffix(name, ".up.sql"), ".down.sql")
+		parts := strings.SplitN(base, "_", 2)
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration filename %q: %w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: base}
+			byVersion[version] = m
+		}
+
+		contents, err := migrationFiles.ReadFile("migrations/" + name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", name, err)
+		}
+
+		if isUp {
+			m.up = string(contents)
+		} else {
+			m.down = string(contents)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+
+	return migrations, nil
+}
+
+// Migrate applies any pending migrations in order, each inside its own
+// transaction, recording progress in schema_migrations. It replaces the old
+// InitSchema, which dropped emails/tokens/accounts on every startup.
+func (db *DB) Migrate() error {
+	if _, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := db.conn.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[v] = true
+	}
+	rows.Close()
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	knownMax := 0
+	for _, m := range migrations {
+		if m.version > knownMax {
+			knownMax = m.version
+		}
+	}
+	for v := range applied {
+		if v > knownMax {
+			return fmt.Errorf("database schema is at version %d, but this binary only knows migrations up to version %d - refusing to start against a newer schema (upgrade the binary first)", v, knownMax)
+		}
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		tx, err := db.conn.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", m.version, err)
+		}
+
+		if _, err := tx.Exec(m.up); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d (%s): %w", m.version, m.name, err)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, m.version, m.name); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+		}
+	}
+
+	return nil
+}
+
+// MigrationStatus describes one migration's on-disk apply state, for the
+// `migrate status` CLI subcommand.
+type MigrationStatus struct {
+	Version int
+	Name    string
+	Applied bool
+}
+
+// Status reports every known migration and whether it has been applied to
+// this database yet, in version order.
+func (db *DB) Status() ([]MigrationStatus, error) {
+	if _, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := db.conn.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		applied[v] = true
+	}
+	rows.Close()
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		statuses[i] = MigrationStatus{Version: m.version, Name: m.name, Applied: applied[m.version]}
+	}
+	return statuses, nil
+}
+
+// StepDown rolls back only the most recently applied migration, unlike
+// Reset which tears down and re-applies everything. Returns without error
+// (and without doing anything) if no migrations have been applied.
+func (db *DB) StepDown() error {
+	rows, err := db.conn.Query(`SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1`)
+	if err != nil {
+		return fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	var version int
+	found := false
+	if rows.Next() {
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		found = true
+	}
+	rows.Close()
+	if !found {
+		return nil
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	var target *migration
+	for i := range migrations {
+		if migrations[i].version == version {
+			target = &migrations[i]
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("schema_migrations records version %d as applied, but this binary has no such migration", version)
+	}
+	if target.down == "" {
+		return fmt.Errorf("migration %d (%s) has no down script", target.version, target.name)
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin rollback of migration %d: %w", version, err)
+	}
+	if _, err := tx.Exec(target.down); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to roll back migration %d (%s): %w", target.version, target.name, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, version); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to unrecord migration %d: %w", version, err)
+	}
+	return tx.Commit()
+}
+
+// Reset drops all known tables by running every migration's down script in
+// reverse, then re-applies them from scratch. This is the old InitSchema
+// behavior, now opt-in via the --reset CLI flag instead of automatic.
+func (db *DB) Reset() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].down == "" {
+			continue
+		}
+		if _, err := db.conn.Exec(migrations[i].down); err != nil {
+			return fmt.Errorf("failed to roll back migration %d: %w", migrations[i].version, err)
+		}
+	}
+
+	if _, err := db.conn.Exec(`DROP TABLE IF EXISTS schema_migrations`); err != nil {
+		return fmt.Errorf("failed to drop schema_migrations: %w", err)
+	}
+
+	return db.Migrate()
+}
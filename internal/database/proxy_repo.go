@@ -0,0 +1,196 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProxyStats holds the adaptive-selection state for a single proxy,
+// persisted so it survives across crawler restarts
+type ProxyStats struct {
+	AvgLatencyMs  int64
+	CooldownUntil time.Time // zero value means no cooldown
+}
+
+// ProxyRepository handles proxy operations
+type ProxyRepository struct {
+	db *sql.DB
+}
+
+// NewProxyRepository creates a new proxy repository
+func NewProxyRepository(db *DB) *ProxyRepository {
+	return &ProxyRepository{db: db.GetConn()}
+}
+
+// AddProxy adds a new proxy
+func (pr *ProxyRepository) AddProxy(proxyURL string) error {
+	_, err := pr.db.Exec(`
+		INSERT OR IGNORE INTO proxies (url) VALUES (?)
+	`, proxyURL)
+	return err
+}
+
+// AddProxies adds multiple proxies (batch insert)
+func (pr *ProxyRepository) AddProxies(proxies []string) error {
+	if len(proxies) == 0 {
+		return nil
+	}
+
+	tx, err := pr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO proxies (url) VALUES (?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, proxyURL := range proxies {
+		if _, err := stmt.Exec(proxyURL); err != nil {
+			return fmt.Errorf("failed to insert proxy: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetValidProxies returns all valid proxies
+func (pr *ProxyRepository) GetValidProxies() ([]string, error) {
+	rows, err := pr.db.Query(`
+		SELECT url FROM proxies
+		WHERE is_valid = 1
+		ORDER BY COALESCE(last_used_at, created_at) ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var proxies []string
+	for rows.Next() {
+		var proxyURL string
+		if err := rows.Scan(&proxyURL); err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, proxyURL)
+	}
+
+	return proxies, rows.Err()
+}
+
+// MarkProxyAsUsed updates the last used timestamp
+func (pr *ProxyRepository) MarkProxyAsUsed(proxyURL string) error {
+	_, err := pr.db.Exec(`
+		UPDATE proxies
+		SET last_used_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE url = ?
+	`, proxyURL)
+	return err
+}
+
+// InvalidateProxy marks a proxy as invalid
+func (pr *ProxyRepository) InvalidateProxy(proxyURL string) error {
+	_, err := pr.db.Exec(`
+		UPDATE proxies
+		SET is_valid = 0,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE url = ?
+	`, proxyURL)
+	return err
+}
+
+// IncrementProxyFailure increments failure count
+func (pr *ProxyRepository) IncrementProxyFailure(proxyURL string) error {
+	_, err := pr.db.Exec(`
+		UPDATE proxies
+		SET failure_count = failure_count + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE url = ?
+	`, proxyURL)
+	return err
+}
+
+// GetValidProxyCount returns the count of valid proxies
+func (pr *ProxyRepository) GetValidProxyCount() (int, error) {
+	var count int
+	err := pr.db.QueryRow(`
+		SELECT COUNT(*) FROM proxies WHERE is_valid = 1
+	`).Scan(&count)
+	return count, err
+}
+
+// ImportProxiesFromFile imports proxies from existing file
+func (pr *ProxyRepository) ImportProxiesFromFile(proxies []string) error {
+	return pr.AddProxies(proxies)
+}
+
+// UpdateProxyStats persists the adaptive-selection state for a proxy so the
+// weighted selector can favor it (or skip it during a cooldown) again after
+// a restart
+func (pr *ProxyRepository) UpdateProxyStats(proxyURL string, stats ProxyStats) error {
+	var cooldown interface{}
+	if !stats.CooldownUntil.IsZero() {
+		cooldown = stats.CooldownUntil
+	}
+
+	_, err := pr.db.Exec(`
+		UPDATE proxies
+		SET avg_latency_ms = ?,
+			cooldown_until = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE url = ?
+	`, stats.AvgLatencyMs, cooldown, proxyURL)
+	return err
+}
+
+// GetProxyStatsMap returns the persisted adaptive-selection state for the
+// given proxies, keyed by URL, so a freshly-initialized ProxyManager can
+// resume weighting/cooldowns from where the last run left off
+func (pr *ProxyRepository) GetProxyStatsMap(proxies []string) (map[string]ProxyStats, error) {
+	stats := make(map[string]ProxyStats, len(proxies))
+	if len(proxies) == 0 {
+		return stats, nil
+	}
+
+	placeholders := make([]string, len(proxies))
+	args := make([]interface{}, len(proxies))
+	for i, proxyURL := range proxies {
+		placeholders[i] = "?"
+		args[i] = proxyURL
+	}
+
+	query := fmt.Sprintf(`
+		SELECT url, avg_latency_ms, cooldown_until FROM proxies
+		WHERE url IN (%s)
+	`, strings.Join(placeholders, ","))
+
+	rows, err := pr.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var proxyURL string
+		var latency int64
+		var cooldown sql.NullTime
+
+		if err := rows.Scan(&proxyURL, &latency, &cooldown); err != nil {
+			return nil, err
+		}
+
+		s := ProxyStats{AvgLatencyMs: latency}
+		if cooldown.Valid {
+			s.CooldownUntil = cooldown.Time
+		}
+		stats[proxyURL] = s
+	}
+
+	return stats, rows.Err()
+}
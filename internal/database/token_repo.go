@@ -3,8 +3,18 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 )
 
+// TokenStats holds the adaptive-selection state for a single token,
+// persisted so it survives across crawler restarts
+type TokenStats struct {
+	SuccessEWMA   float64
+	AvgLatencyMs  int64
+	CooldownUntil time.Time // zero value means no cooldown
+}
+
 // TokenRepository handles token operations
 type TokenRepository struct {
 	db *sql.DB
@@ -116,7 +126,153 @@ func (tr *TokenRepository) GetValidTokenCount() (int, error) {
 	return count, err
 }
 
+// InvalidateStaleTokens marks every currently-valid token as invalid once
+// it has either failed failureThreshold times in a row or gone idle for
+// longer than idleTTL, stamping invalidated_at so callers (account
+// rotation GC) can tell how long it's been out of rotation. Returns the
+// number of tokens invalidated.
+func (tr *TokenRepository) InvalidateStaleTokens(failureThreshold int, idleTTL time.Duration) (int, error) {
+	idleCutoff := time.Now().Add(-idleTTL)
+	result, err := tr.db.Exec(`
+		UPDATE tokens
+		SET is_valid = 0,
+			invalidated_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE is_valid = 1
+		AND (failure_count >= ? OR (last_used_at IS NOT NULL AND last_used_at < ?))
+	`, failureThreshold, idleCutoff)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// GetInvalidTokens returns every token currently marked invalid, so GC can
+// re-probe them and bring back any that actually still work.
+func (tr *TokenRepository) GetInvalidTokens() ([]string, error) {
+	rows, err := tr.db.Query(`
+		SELECT token FROM tokens WHERE is_valid = 0
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// RevalidateToken re-enables a token after it's passed a fresh probe,
+// clearing the failure count and invalidated_at so it starts its next run
+// in rotation with a clean slate.
+func (tr *TokenRepository) RevalidateToken(token string) error {
+	_, err := tr.db.Exec(`
+		UPDATE tokens
+		SET is_valid = 1,
+			failure_count = 0,
+			invalidated_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE token = ?
+	`, token)
+	return err
+}
+
+// GetOldestInvalidation returns when the longest-invalid token was
+// invalidated, used to decide when it's time to rotate in a fresh account.
+// It returns the zero Time if no token is currently invalid or none carry an
+// invalidated_at timestamp (e.g. tokens invalidated before this column
+// existed).
+func (tr *TokenRepository) GetOldestInvalidation() (time.Time, error) {
+	var invalidatedAt sql.NullTime
+	err := tr.db.QueryRow(`
+		SELECT MIN(invalidated_at) FROM tokens WHERE is_valid = 0
+	`).Scan(&invalidatedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !invalidatedAt.Valid {
+		return time.Time{}, nil
+	}
+	return invalidatedAt.Time, nil
+}
+
 // ImportTokensFromFile imports tokens from existing file
 func (tr *TokenRepository) ImportTokensFromFile(tokens []string) error {
 	return tr.AddTokens(tokens)
 }
+
+// UpdateTokenStats persists the adaptive-selection state for a token so the
+// weighted selector can favor it (or skip it during a cooldown) again after
+// a restart
+func (tr *TokenRepository) UpdateTokenStats(token string, stats TokenStats) error {
+	var cooldown interface{}
+	if !stats.CooldownUntil.IsZero() {
+		cooldown = stats.CooldownUntil
+	}
+
+	_, err := tr.db.Exec(`
+		UPDATE tokens
+		SET success_ewma = ?,
+			avg_latency_ms = ?,
+			cooldown_until = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE token = ?
+	`, stats.SuccessEWMA, stats.AvgLatencyMs, cooldown, token)
+	return err
+}
+
+// GetTokenStatsMap returns the persisted adaptive-selection state for the
+// given tokens, keyed by token, so a freshly-initialized TokenManager can
+// resume weighting/cooldowns from where the last run left off
+func (tr *TokenRepository) GetTokenStatsMap(tokens []string) (map[string]TokenStats, error) {
+	stats := make(map[string]TokenStats, len(tokens))
+	if len(tokens) == 0 {
+		return stats, nil
+	}
+
+	placeholders := make([]string, len(tokens))
+	args := make([]interface{}, len(tokens))
+	for i, token := range tokens {
+		placeholders[i] = "?"
+		args[i] = token
+	}
+
+	query := fmt.Sprintf(`
+		SELECT token, success_ewma, avg_latency_ms, cooldown_until FROM tokens
+		WHERE token IN (%s)
+	`, strings.Join(placeholders, ","))
+
+	rows, err := tr.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var token string
+		var ewma float64
+		var latency int64
+		var cooldown sql.NullTime
+
+		if err := rows.Scan(&token, &ewma, &latency, &cooldown); err != nil {
+			return nil, err
+		}
+
+		s := TokenStats{SuccessEWMA: ewma, AvgLatencyMs: latency}
+		if cooldown.Valid {
+			s.CooldownUntil = cooldown.Time
+		}
+		stats[token] = s
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,173 @@
+// Package events fans a crawl event out to a configurable set of outbound
+// destinations (a generic webhook, push notifications) independently of
+// the per-result internal/output sinks. Where internal/output records
+// what a crawl found, this package reports what's happening to it - a
+// token going invalid, all tokens failing, a batch finishing - for an
+// operator's monitoring/alerting stack to react to in near-real-time.
+//
+// A generic HMAC-signed webhook sink is implemented; a Firebase Cloud
+// Messaging sink is recognized by models.EventSinkSpec but fails at
+// construction (see fcm.go) since its OAuth2/service-account client isn't
+// vendored in this build and there's no network access to add it.
+//
+// Like output.FanOut, each configured Sink runs on its own bounded-queue
+// worker goroutine, so a slow or unreachable destination can't block the
+// crawl hot path that emits events - Bus.Emit only blocks long enough to
+// enqueue, dropping (with a logged warning) if that sink's queue is still
+// backed up from an earlier slowdown.
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"linkedin-crawler/internal/logging"
+	"linkedin-crawler/internal/models"
+)
+
+// EventType identifies what happened.
+type EventType string
+
+const (
+	// ProfileHit fires when a query returns a profile for the email.
+	ProfileHit EventType = "profile_hit"
+	// ProfileMiss fires when a query succeeds (200) but finds no profile.
+	ProfileMiss EventType = "profile_miss"
+	// TokenRateLimited fires when a token gets a 429/999 back from LinkedIn.
+	TokenRateLimited EventType = "token_rate_limited"
+	// TokenInvalidated fires when a token is removed from the pool after a
+	// 401/403/424.
+	TokenInvalidated EventType = "token_invalidated"
+	// AllTokensFailed fires when no token in the pool remains usable.
+	AllTokensFailed EventType = "all_tokens_failed"
+	// BatchComplete fires once, on crawl shutdown.
+	BatchComplete EventType = "batch_complete"
+)
+
+// Event describes one thing that happened during a crawl run.
+type Event struct {
+	Type EventType `json:"type"`
+
+	// Email is the address the event is about; empty for BatchComplete.
+	// AllTokensFailed carries the email whose request found the pool
+	// exhausted.
+	Email string `json:"email,omitempty"`
+
+	// StatusCode is the HTTP status that triggered the event, when
+	// applicable (0 otherwise).
+	StatusCode int `json:"status_code,omitempty"`
+
+	// RemainingEmails is how many emails are still pending, from
+	// EmailRepository.CountRemainingEmails, at the moment the event fired.
+	// -1 when the caller didn't supply a counter.
+	RemainingEmails int `json:"remaining_emails"`
+
+	Ts time.Time `json:"ts"`
+}
+
+// Sink is one destination for crawl events. Bus runs each Sink on a single
+// worker goroutine, so implementations never need to guard against
+// concurrent calls to Emit.
+type Sink interface {
+	Emit(ctx context.Context, event Event) error
+	Close() error
+}
+
+// queueSize bounds how many pending events a sink's worker buffers before
+// Bus starts dropping events for that sink rather than blocking the
+// emitter.
+const queueSize = 256
+
+type worker struct {
+	name string
+	sink Sink
+	ch   chan Event
+	wg   sync.WaitGroup
+}
+
+func newWorker(name string, sink Sink) *worker {
+	w := &worker{name: name, sink: sink, ch: make(chan Event, queueSize)}
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+func (w *worker) run() {
+	defer w.wg.Done()
+	for e := range w.ch {
+		if err := w.sink.Emit(context.Background(), e); err != nil {
+			logging.Logger.Warn("⚠️ event sink emit failed", "sink", w.name, "type", e.Type, "error", err)
+		}
+	}
+}
+
+func (w *worker) enqueue(e Event) {
+	select {
+	case w.ch <- e:
+	default:
+		logging.Logger.Warn("⚠️ event sink queue full, dropping event", "sink", w.name, "type", e.Type)
+	}
+}
+
+// Bus distributes Emit calls to every configured Sink without letting a
+// slow one hold up the others or the caller.
+type Bus struct {
+	workers []*worker
+}
+
+// New builds a Bus from specs, in order.
+func New(specs []models.EventSinkSpec) (*Bus, error) {
+	b := &Bus{}
+	for _, spec := range specs {
+		sink, err := build(spec)
+		if err != nil {
+			b.Close()
+			return nil, fmt.Errorf("events: failed to configure %s sink: %w", spec.Type, err)
+		}
+		b.workers = append(b.workers, newWorker(spec.Type, sink))
+	}
+	return b, nil
+}
+
+func build(spec models.EventSinkSpec) (Sink, error) {
+	switch spec.Type {
+	case "webhook":
+		return newWebhookSink(spec.URL, spec.Secret, spec.Path)
+	case "fcm":
+		return newFCMSink(spec)
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", spec.Type)
+	}
+}
+
+// Emit enqueues event on every sink's worker. It never blocks on a slow
+// sink: a full queue drops the event for that sink only, logging a
+// warning, rather than stalling the caller.
+func (b *Bus) Emit(event Event) {
+	if b == nil {
+		return
+	}
+	for _, w := range b.workers {
+		w.enqueue(event)
+	}
+}
+
+// Close drains and stops every worker, then closes its sink. Returns the
+// first error encountered after attempting all of them.
+func (b *Bus) Close() error {
+	if b == nil {
+		return nil
+	}
+	var first error
+	for _, w := range b.workers {
+		close(w.ch)
+		w.wg.Wait()
+		if err := w.sink.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	b.workers = nil
+	return first
+}
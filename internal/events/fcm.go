@@ -0,0 +1,21 @@
+package events
+
+import (
+	"fmt"
+
+	"linkedin-crawler/internal/models"
+)
+
+// newFCMSink backs the "fcm" EventSinkSpec type: pushing events as Firebase
+// Cloud Messaging notifications via
+// fcm.googleapis.com/v1/projects/{id}/messages:send. That endpoint needs an
+// OAuth2 access token minted from a service-account JSON key
+// (golang.org/x/oauth2/google's JWT config flow), and neither that package
+// nor a Firebase SDK is vendored in this build, with no network access here
+// to add one - so rather than faking the auth handshake, construction fails
+// with a clear error naming the missing dependency, the same honest-
+// substitute approach output.newParquetSink/newStreamSink use for their own
+// unvendored client libraries.
+func newFCMSink(spec models.EventSinkSpec) (Sink, error) {
+	return nil, fmt.Errorf("events: fcm sink requires golang.org/x/oauth2/google and a Firebase service account, neither of which is vendored in this build")
+}
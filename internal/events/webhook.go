@@ -0,0 +1,131 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	webhookAttempts       = 4
+	webhookBackoffBase    = 500 * time.Millisecond
+	webhookBackoffCap     = 10 * time.Second
+	webhookRequestTimeout = 10 * time.Second
+)
+
+// webhookSink POSTs every event to a configured URL, HMAC-SHA256 signing
+// the body into an X-Signature-256 header, the same contract as
+// output.webhookSink uses for crawl results. 5xx responses (and connection
+// failures) are retried with exponential backoff; if every attempt still
+// fails, the event is appended to a spillover file instead of being
+// dropped.
+type webhookSink struct {
+	url    string
+	secret string
+	client *http.Client
+
+	mu    sync.Mutex
+	spill *os.File
+}
+
+func newWebhookSink(url, secret, spillPath string) (*webhookSink, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook sink: url is required")
+	}
+	if spillPath == "" {
+		spillPath = "events_webhook_spill.ndjson"
+	}
+
+	f, err := os.OpenFile(spillPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("webhook sink: failed to open spillover file %s: %w", spillPath, err)
+	}
+
+	return &webhookSink{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: webhookRequestTimeout},
+		spill:  f,
+	}, nil
+}
+
+func (s *webhookSink) Emit(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("webhook sink: failed to marshal event: %w", err)
+	}
+
+	if err := s.postWithRetry(ctx, body); err != nil {
+		return s.spillToDisk(body, err)
+	}
+	return nil
+}
+
+func (s *webhookSink) postWithRetry(ctx context.Context, body []byte) error {
+	backoff := webhookBackoffBase
+	var lastErr error
+
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("webhook sink: failed to build request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Signature-256", s.sign(body))
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			lastErr = err
+		} else {
+			resp.Body.Close()
+			if resp.StatusCode < 500 {
+				if resp.StatusCode >= 300 {
+					return fmt.Errorf("webhook sink: %s returned %d", s.url, resp.StatusCode)
+				}
+				return nil
+			}
+			lastErr = fmt.Errorf("webhook sink: %s returned %d", s.url, resp.StatusCode)
+		}
+
+		if attempt < webhookAttempts {
+			time.Sleep(backoff + time.Duration(rand.Int63n(int64(backoff)/2+1)))
+			backoff *= 2
+			if backoff > webhookBackoffCap {
+				backoff = webhookBackoffCap
+			}
+		}
+	}
+
+	return lastErr
+}
+
+func (s *webhookSink) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *webhookSink) spillToDisk(body []byte, causeErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.spill.Write(append(body, '\n')); err != nil {
+		return fmt.Errorf("webhook sink: endpoint unreachable (%v) and spill failed: %w", causeErr, err)
+	}
+	return s.spill.Sync()
+}
+
+func (s *webhookSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spill.Close()
+}
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+type ctxKey struct{}
+
+// WithFields returns a context carrying a logger that includes args on
+// every line logged through FromContext(ctx), layered on top of whatever
+// fields an outer WithFields call already attached. Use it to annotate a
+// context once - e.g. with retry_attempt, email, token_id, batch_size - and
+// have every log line taken from it downstream auto-include them, instead
+// of re-passing the same fields at each call site.
+func WithFields(ctx context.Context, args ...any) context.Context {
+	return context.WithValue(ctx, ctxKey{}, FromContext(ctx).With(args...))
+}
+
+// FromContext returns the logger attached to ctx by WithFields or
+// IntoContext, or Logger if ctx carries none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return Logger
+}
+
+// IntoContext returns a context whose logger (see FromContext) is l,
+// replacing whatever was attached before rather than layering onto it.
+// Use this instead of WithFields when the base logger itself needs to
+// change - e.g. swapping in a logger that routes through
+// AutoCrawler.LogLine instead of the package-wide Logger - then layer
+// per-call fields on top with WithFields as usual.
+func IntoContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
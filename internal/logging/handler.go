@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+)
+
+// lineHandler formats records the same way the default text handler does,
+// but hands each finished line to sink instead of writing it to an
+// io.Writer. It exists so call sites that currently go through
+// AutoCrawler.LogLine (to land in the crawler's own buffered log view) can
+// become slog-structured without losing that behavior.
+type lineHandler struct {
+	sink    func(string)
+	handler slog.Handler
+}
+
+// NewLineHandler returns an slog.Handler whose output is passed to sink one
+// formatted line at a time.
+func NewLineHandler(sink func(string)) slog.Handler {
+	h := &lineHandler{sink: sink}
+	h.handler = slog.NewTextHandler(lineWriter{h}, nil)
+	return h
+}
+
+// NewLineLogger returns a logger built on NewLineHandler, for call sites
+// that need to keep reporting through sink (e.g. AutoCrawler.LogLine) while
+// gaining structured fields.
+func NewLineLogger(sink func(string)) *slog.Logger {
+	return slog.New(NewLineHandler(sink))
+}
+
+// lineWriter adapts io.Writer to lineHandler.sink, trimming the trailing
+// newline slog.TextHandler always appends to each record.
+type lineWriter struct {
+	h *lineHandler
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w.h.sink(string(bytes.TrimRight(p, "\n")))
+	return len(p), nil
+}
+
+func (h *lineHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *lineHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *lineHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &lineHandler{sink: h.sink, handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *lineHandler) WithGroup(name string) slog.Handler {
+	return &lineHandler{sink: h.sink, handler: h.handler.WithGroup(name)}
+}
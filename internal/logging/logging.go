@@ -0,0 +1,72 @@
+// Package logging provides the structured logger used by the orchestrator
+// and token validation, replacing ad-hoc fmt.Printf calls so operators can
+// correlate log lines by email/token over a long-running batch.
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"linkedin-crawler/internal/utils"
+)
+
+// StructuredLogger is the minimal structured-logging surface the
+// orchestrator codes against, rather than *slog.Logger directly, so a call
+// site can be handed any of the sinks in this package (or Logger itself,
+// which satisfies it) interchangeably. Each method logs msg at its level
+// with the given key-value pairs, exactly like the matching *slog.Logger
+// method.
+type StructuredLogger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Logger is the process-wide structured logger. Text-handler output keeps
+// the terminal readable while still being field-structured for anyone
+// piping it into a log aggregator. Configure replaces it with one built
+// from CLI flags/LOG_LEVEL once main has parsed them.
+var Logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+// Configure rebuilds Logger from format ("text", the default, for
+// terminals, or "json" for shipping to Loki/ELK) and level ("debug",
+// "info" - the default, "warn", or "error"). Both default when empty, so
+// callers can pass flag values straight through without checking for the
+// zero value themselves. It's meant to be called once, from main, right
+// after flags are parsed and before anything else logs.
+func Configure(format, level string) error {
+	lvl := slog.LevelInfo
+	if level != "" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return fmt.Errorf("invalid log level %q: %w", level, err)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch format {
+	case "", "text":
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	default:
+		return fmt.Errorf("unknown log format %q (want \"text\" or \"json\")", format)
+	}
+
+	Logger = slog.New(handler)
+	return nil
+}
+
+// WithEmail returns a logger scoped to a single email, for correlating every
+// log line touching that email across retries.
+func WithEmail(email string) *slog.Logger {
+	return Logger.With("email", email)
+}
+
+// WithToken returns a logger scoped to a single token, identified by its
+// fingerprint rather than the raw value so tokens never land in logs.
+func WithToken(token string) *slog.Logger {
+	return Logger.With("token", utils.TokenFingerprint(token))
+}
@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// NewFileLogger returns a StructuredLogger that appends JSON-lines records
+// to path, one per log call - the format Loki/ELK expect to ingest. The
+// caller must Close the returned io.Closer on shutdown to release the file.
+func NewFileLogger(path string) (*slog.Logger, io.Closer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file %s: %w", path, err)
+	}
+	return slog.New(slog.NewJSONHandler(f, nil)), f, nil
+}
+
+// NewStdoutLogger returns a StructuredLogger writing to stdout in the given
+// format ("text", the default, or "json"), the same formats Configure
+// accepts.
+func NewStdoutLogger(format string) (*slog.Logger, error) {
+	switch format {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(os.Stdout, nil)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stdout, nil)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (want \"text\" or \"json\")", format)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package logging
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// NewSyslogLogger is unavailable on this platform: log/syslog only builds
+// for Unix. Callers should fall back to NewFileLogger or NewStdoutLogger.
+func NewSyslogLogger(tag string) (*slog.Logger, error) {
+	return nil, errors.New("syslog logging is not supported on this platform")
+}
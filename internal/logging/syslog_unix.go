@@ -0,0 +1,20 @@
+//go:build !windows && !plan9
+
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"log/syslog"
+)
+
+// NewSyslogLogger returns a StructuredLogger that writes to the local
+// syslog daemon under tag, for deployments that already ship host logs via
+// syslog rather than scraping a file or stdout.
+func NewSyslogLogger(tag string) (*slog.Logger, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return slog.New(slog.NewTextHandler(w, nil)), nil
+}
@@ -0,0 +1,245 @@
+// Package metrics tracks crawler health for long-running batches and
+// exposes it in the Prometheus text exposition format. The official
+// client_golang library isn't vendored in this build (no network access to
+// fetch it), so this is a small self-contained counter/gauge/histogram
+// registry that speaks the same wire format - any real Prometheus server
+// can scrape /metrics without knowing the difference.
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// counter is a monotonically increasing value, optionally labeled.
+type counter struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newCounter() *counter { return &counter{values: make(map[string]float64)} }
+
+// Inc increments the counter for labelValue (e.g. a status, a token
+// fingerprint) by 1.
+func (c *counter) Inc(labelValue string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[labelValue]++
+}
+
+// gauge is a value that can go up or down, unlabeled.
+type gauge struct {
+	mu    sync.Mutex
+	value float64
+}
+
+// Set replaces the gauge's current value.
+func (g *gauge) Set(v float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value = v
+}
+
+func (g *gauge) get() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.value
+}
+
+// histogram tracks observation counts against a fixed set of upper bounds,
+// matching Prometheus's cumulative bucket convention.
+type histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+// Observe records a single measurement (in seconds, for the latency
+// histograms) against the histogram's buckets.
+func (h *histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sum += v
+	h.count++
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+}
+
+var defaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics to surface during a crawl run.
+var (
+	EmailsProcessedTotal = newCounter() // label: terminal status (success_with_data, success_without_data, failed, permanent_failed)
+	TokenSuccessTotal    = newCounter() // label: token fingerprint
+	TokenFailureTotal    = newCounter() // label: token fingerprint
+	StatusCodeTotal      = newCounter() // label: HTTP status code
+	RateLimitHitsTotal   = newCounter() // label: token fingerprint
+
+	RequestLatencySeconds = newHistogram(defaultLatencyBuckets)
+	DBQueryLatencySeconds = newHistogram(defaultLatencyBuckets)
+
+	ValidTokenCount = &gauge{}
+	ValidProxyCount = &gauge{}
+	QueueDepth      = &gauge{}
+
+	BatchProcessed  = &gauge{}
+	BatchSuccess    = &gauge{}
+	BatchFailed     = &gauge{}
+	BatchActive     = &gauge{}
+	BatchTotal      = &gauge{}
+	GlobalProcessed = &gauge{}
+	GlobalTotal     = &gauge{}
+)
+
+// RecordTokenOutcome increments the per-token success/failure counter for
+// token (identified by its fingerprint so the raw token never appears in a
+// metric label).
+func RecordTokenOutcome(tokenFingerprint string, success bool) {
+	if success {
+		TokenSuccessTotal.Inc(tokenFingerprint)
+	} else {
+		TokenFailureTotal.Inc(tokenFingerprint)
+	}
+}
+
+func writeCounter(sb *strings.Builder, name, help string, c *counter, labelName string) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(sb, "%s{%s=%q} %g\n", name, labelName, k, c.values[k])
+	}
+}
+
+func writeGauge(sb *strings.Builder, name, help string, g *gauge) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, g.get())
+}
+
+func writeHistogram(sb *strings.Builder, name, help string, h *histogram) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, b := range h.buckets {
+		fmt.Fprintf(sb, "%s_bucket{le=%q} %d\n", name, fmt.Sprintf("%g", b), h.counts[i])
+	}
+	fmt.Fprintf(sb, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(sb, "%s_sum %g\n", name, h.sum)
+	fmt.Fprintf(sb, "%s_count %d\n", name, h.count)
+}
+
+// render returns the full /metrics body in Prometheus text exposition format.
+func render() string {
+	var sb strings.Builder
+
+	writeCounter(&sb, "crawler_emails_processed_total", "Emails processed, by terminal status", EmailsProcessedTotal, "status")
+	writeCounter(&sb, "crawler_token_success_total", "Successful requests per token", TokenSuccessTotal, "token")
+	writeCounter(&sb, "crawler_token_failure_total", "Failed requests per token", TokenFailureTotal, "token")
+	writeCounter(&sb, "crawler_http_status_total", "Responses received, by HTTP status code", StatusCodeTotal, "code")
+	writeCounter(&sb, "crawler_rate_limit_hits_total", "Rate-limit (429/999) responses, by token", RateLimitHitsTotal, "token")
+
+	writeHistogram(&sb, "crawler_request_latency_seconds", "LinkedIn API request latency", RequestLatencySeconds)
+	writeHistogram(&sb, "crawler_db_query_latency_seconds", "SQLite query latency", DBQueryLatencySeconds)
+
+	writeGauge(&sb, "crawler_valid_tokens", "Number of currently valid tokens", ValidTokenCount)
+	writeGauge(&sb, "crawler_valid_proxies", "Number of currently valid proxies", ValidProxyCount)
+	writeGauge(&sb, "crawler_queue_depth", "Emails remaining to process", QueueDepth)
+
+	writeGauge(&sb, "crawler_batch_processed", "Emails processed in the current batch", BatchProcessed)
+	writeGauge(&sb, "crawler_batch_success", "Successful requests in the current batch", BatchSuccess)
+	writeGauge(&sb, "crawler_batch_failed", "Failed requests in the current batch", BatchFailed)
+	writeGauge(&sb, "crawler_batch_active", "In-flight requests in the current batch", BatchActive)
+	writeGauge(&sb, "crawler_batch_total", "Size of the current batch", BatchTotal)
+	writeGauge(&sb, "crawler_global_processed", "Emails processed across the whole run", GlobalProcessed)
+	writeGauge(&sb, "crawler_global_total", "Total emails for the whole run", GlobalTotal)
+
+	return sb.String()
+}
+
+// HealthChecker reports whether the crawler is ready to serve traffic - ok
+// and a human-readable reason, used either way. /healthz calls whatever was
+// last passed to RegisterHealthCheck.
+type HealthChecker func() (ok bool, reason string)
+
+var healthCheck HealthChecker
+
+// RegisterHealthCheck installs the check /healthz runs on each request,
+// replacing any previous one. Call it once the dependencies it checks (the
+// DB connection, token/account repositories) are ready; until then,
+// /healthz reports ok so the process isn't marked unhealthy during its own
+// startup.
+func RegisterHealthCheck(fn HealthChecker) {
+	healthCheck = fn
+}
+
+// Handler returns the /metrics http.Handler.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprint(w, render())
+	})
+}
+
+// StartServer starts the /metrics and /healthz endpoints on addr in the
+// background. An empty addr disables the server (returns nil, nil).
+func StartServer(addr string) (*http.Server, error) {
+	if addr == "" {
+		return nil, nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if healthCheck == nil {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, "ok")
+			return
+		}
+
+		ok, reason := healthCheck()
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, reason)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, reason)
+	})
+
+	server := &http.Server{Addr: addr, Handler: mux}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return nil, err
+		}
+	default:
+	}
+
+	return server, nil
+}
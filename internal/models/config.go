@@ -14,4 +14,331 @@ type Config struct {
 	MinTokens        int
 	MaxTokens        int
 	SleepDuration    time.Duration
+	Storage          StorageConfig
+
+	// ResetDatabase drops and recreates the schema on startup instead of
+	// resuming from it (the --reset CLI flag).
+	ResetDatabase bool
+
+	// LeaseDuration bounds how long a worker may hold an email leased via
+	// EmailRepo.LeaseEmails before another worker is allowed to reclaim it.
+	LeaseDuration time.Duration
+
+	Proxy ProxyConfig
+
+	// MetricsAddr is the listen address (e.g. ":9090") for the /metrics and
+	// /healthz HTTP endpoints. Empty disables the metrics server.
+	MetricsAddr string
+
+	// ProgressMode selects how BatchProcessor reports crawl progress: "tty"
+	// (rich progress bars), "json" (one JSON line per tick, for ingestion by
+	// external monitors), "prometheus" (gauges on /metrics only, no stdout
+	// output), or "auto" (tty when stderr is a terminal, json otherwise).
+	ProgressMode string
+
+	// TokenRateLimit bounds how many requests per second each individual
+	// token may make, via the token-bucket limiter in crawler/ratelimit.
+	TokenRateLimit RateLimitConfig
+
+	// CheckpointPath is where the write-ahead log of per-email processing
+	// transitions (orchestrator/checkpoint) is written.
+	CheckpointPath string
+
+	// Resume replays CheckpointPath on startup to recover from a hard
+	// crash (SIGKILL, power loss) instead of relying only on the SQLite
+	// lease state written at the last graceful shutdown (the --resume CLI
+	// flag).
+	Resume bool
+
+	// Outputs configures the fan-out destinations BatchProcessor writes
+	// crawl results to (see internal/output). Empty defaults to the
+	// original single "text" (hit.txt) sink.
+	Outputs []OutputSpec
+
+	// Coordinator selects the orchestrator/coordinator backend that leases
+	// out emails/accounts and pools tokens across workers (the
+	// --coordinator flag): "local" (default, single-process) or a
+	// "redis://" URL for distributed mode.
+	Coordinator string
+
+	// Notifier configures where operational alerts (a stalled retry loop, a
+	// spike in permanent failures, a completed batch) are sent.
+	Notifier NotifierParams
+
+	// LeaderElection selects the coordinator.LeaderElector backend used to
+	// pick which of several cooperating workers runs leader-only phases
+	// (RetryHandler.RetryFailedEmails, StateManager.SaveStateOnShutdown):
+	// "local" (default, single-process, always leader) or a "zk://"/
+	// "etcd://" URL for distributed mode.
+	LeaderElection string
+
+	// ProfileBackend selects the crawler.ProfileBackend BatchProcessor
+	// fetches profiles through: "linkedin" (default, the real LinkedIn
+	// API via QueryService) or "rocketreach" (not implemented yet).
+	ProfileBackend string
+
+	// GCInterval is how often AutoCrawler.GC sweeps the in-memory email
+	// tracking maps and token pool for stale entries.
+	GCInterval time.Duration
+
+	// EmailMaxStale is how long an entry may sit in the email tracking
+	// maps before GC flushes it to EmailRepo and drops it from memory.
+	EmailMaxStale time.Duration
+
+	// TokenMaxStale is how long the token pool may go without
+	// re-validation before GC re-checks it via validateExistingTokens.
+	TokenMaxStale time.Duration
+
+	// DBGCInterval is how often AutoCrawler.DBGC sweeps the tokens/accounts/
+	// emails tables - distinct from GCInterval, which only touches the
+	// in-memory email maps and the file-based token pool.
+	DBGCInterval time.Duration
+
+	// TokenFailureThreshold is how many consecutive failures a token may
+	// accumulate before DBGC marks it invalid in the DB.
+	TokenFailureThreshold int
+
+	// TokenIdleTTL is how long a token may go unused before DBGC marks it
+	// invalid in the DB, independent of its failure count.
+	TokenIdleTTL time.Duration
+
+	// AccountRotationTTL is how long the oldest invalidated token may sit
+	// unrevalidated before DBGC rotates in a fresh account via
+	// auth.LoginService.
+	AccountRotationTTL time.Duration
+
+	// EmailArchiveRetention is how long an email may sit as
+	// permanent_failed before DBGC moves it into emails_archive.
+	EmailArchiveRetention time.Duration
+
+	// LogSink selects where AutoCrawler's structured run log (see
+	// internal/logging.StructuredLogger) is written: "file" (default,
+	// JSON lines to LogFilePath), "stdout", or "syslog".
+	LogSink string
+
+	// LogFilePath is the JSON-lines log file written when LogSink is
+	// "file".
+	LogFilePath string
+
+	// LogSyslogTag is the tag records are sent under when LogSink is
+	// "syslog".
+	LogSyslogTag string
+
+	// APIAddr is the listen address (e.g. ":8090") for the control-plane
+	// REST API (internal/api). Empty disables it.
+	APIAddr string
+
+	// APIToken, when set, is the shared secret every control-plane request
+	// must present as "Authorization: Bearer <token>" - the API can add
+	// accounts, revoke tokens, and stop the crawler, so it shouldn't be
+	// exposed unauthenticated past localhost. Empty leaves the API open,
+	// for local/loopback-only use.
+	APIToken string
+
+	// WorkerID pins the identity this run's email leases, leader-election
+	// campaigns, and coordinator heartbeats are tracked under (the
+	// --worker-id flag). Empty (the default) generates a fresh UUID per
+	// run, which is fine for a single node; a distributed deployment that
+	// wants a stable identity across restarts (e.g. one per pod) should
+	// set this explicitly.
+	WorkerID string
+
+	// EventSinks configures the internal/events destinations crawl-lifecycle
+	// events (a token going invalid, all tokens failing, a batch finishing)
+	// are pushed to - distinct from Outputs, which carries per-email crawl
+	// results rather than operational events.
+	EventSinks []EventSinkSpec
+
+	// Browser configures auth.BrowserManager's pool of Chrome contexts used
+	// to log freshly-rotated-in accounts into Teams.
+	Browser BrowserConfig
+}
+
+// BrowserConfig configures auth.BrowserManager's Chrome context pool.
+type BrowserConfig struct {
+	// PoolSize is how many Chrome contexts BrowserManager keeps ready,
+	// reused across logins rather than launching one per account. 0 (the
+	// zero value) is treated as 1, the original one-at-a-time behavior.
+	PoolSize int
+
+	// Headless runs Chrome without a visible window. false (the original
+	// behavior) is the default.
+	Headless bool
+
+	// RemoteDebuggingURL, when set, attaches to an already-running
+	// Chrome/Chromium's remote debugging endpoint (e.g. browserless/chrome
+	// in Docker) instead of spawning a local Chrome process per slot.
+	RemoteDebuggingURL string
+
+	// ProxyURLs assigns outbound proxies to pool slots round-robin, one
+	// "scheme://host:port" per slot. Empty means no proxy.
+	ProxyURLs []string
+
+	// UserDataDir is the parent directory each pool slot gets its own
+	// subdirectory under, so login cookies survive across runs. Empty (the
+	// original behavior) uses a fresh temporary profile every run.
+	UserDataDir string
+}
+
+// NotifierParams selects and configures the notifier.Notifier backend.
+type NotifierParams struct {
+	// Kind selects the backend: "smtp", "webhook", "telegram", "log", or "" (same as
+	// "null" - alerts are discarded). Falls back to discarding alerts if
+	// the backend it names has no address configured, rather than failing
+	// the crawl over missing alerting config.
+	Kind string
+
+	// SMTPAddr is the "host:port" of the mail server an SMTPNotifier
+	// authenticates to.
+	SMTPAddr string
+
+	// SMTPUser and SMTPPass are PLAIN-auth credentials for SMTPAddr.
+	SMTPUser string
+	SMTPPass string
+
+	// From is the sender address for an SMTPNotifier.
+	From string
+
+	// To lists the recipient addresses for an SMTPNotifier.
+	To []string
+
+	// WebhookURL is the endpoint a WebhookNotifier POSTs alerts to.
+	WebhookURL string
+
+	// TelegramBotToken and TelegramChatID configure a Telegram bot backend
+	// (Kind == "telegram"): TelegramBotToken authenticates as the bot,
+	// TelegramChatID is the chat alerts are pushed to and commands are
+	// accepted from. Either being empty makes the notifier a no-op,
+	// matching every other backend's missing-address fallback.
+	TelegramBotToken string
+	TelegramChatID   string
+}
+
+// OutputSpec configures one output.Sink. BatchProcessor fans every crawl
+// result out to all configured sinks concurrently, so a slow one (e.g. an
+// unreachable webhook) can't stall the others.
+type OutputSpec struct {
+	// Type selects the sink implementation: "text" (the original hit.txt
+	// file), "csv", "ndjson", "webhook", "template", "parquet", "kafka", or
+	// "redisstream". The last three fail at construction with a clear error
+	// instead of silently dropping writes - see internal/output's package
+	// doc for why.
+	Type string
+
+	// Path is the destination file for "csv"/"ndjson"/"template", or the
+	// spillover file a "webhook" sink appends to while its endpoint is
+	// unreachable. The "text" sink ignores it and writes through the active
+	// crawler's hit.txt handle.
+	Path string
+
+	// URL is the endpoint a "webhook" sink POSTs results to.
+	URL string
+
+	// Secret HMAC-SHA256-signs "webhook" payloads into the
+	// X-Signature-256 request header.
+	Secret string
+
+	// Topic names the destination queue/stream for "kafka"/"redisstream".
+	Topic string
+
+	// Template is the Go text/template body rendered once per result for
+	// a "template" sink (plus a handful of sprig-like string helpers - see
+	// internal/output/template.go). Ignored for every other Type.
+	Template string
+}
+
+// EventSinkSpec configures one events.Sink. events.Bus fans every
+// crawl-lifecycle event out to all configured sinks concurrently, so a slow
+// one (e.g. an unreachable webhook) can't stall the others or the crawl.
+type EventSinkSpec struct {
+	// Type selects the sink implementation: "webhook" or "fcm". "fcm" fails
+	// at construction with a clear error instead of silently dropping
+	// events - see internal/events's package doc for why.
+	Type string
+
+	// URL is the endpoint a "webhook" sink POSTs events to.
+	URL string
+
+	// Secret HMAC-SHA256-signs "webhook" payloads into the
+	// X-Signature-256 request header.
+	Secret string
+
+	// Path is the spillover file a "webhook" sink appends to while its
+	// endpoint is unreachable.
+	Path string
+
+	// FCMProjectID and FCMCredentialsPath configure the "fcm" sink's
+	// target project and service-account key. Ignored for every other
+	// Type.
+	FCMProjectID       string
+	FCMCredentialsPath string
+}
+
+// RateLimitConfig configures the per-token token-bucket limiter.
+type RateLimitConfig struct {
+	// RatePerSecond is the sustained rate each token's bucket refills at.
+	RatePerSecond float64
+
+	// Burst is the bucket's capacity: how many requests a token can make
+	// back-to-back before it has to wait for refill.
+	Burst float64
+
+	// BreakerCooldownCap bounds how long a token's circuit breaker can make
+	// it sit out after repeated 429/999 responses; the cooldown itself
+	// starts small and doubles on each consecutive trip up to this cap.
+	// Zero uses ratelimit's built-in default.
+	BreakerCooldownCap time.Duration
+}
+
+// ProxyConfig controls the outbound proxy pool used alongside tokens.
+type ProxyConfig struct {
+	// FilePath is where proxies are loaded from on startup (mirrors
+	// TokensFilePath/AccountsFilePath), one "scheme://host:port" per line.
+	FilePath string
+
+	// MinHealthy is the number of valid proxies below which the batch
+	// processor logs a warning when it loads the pool. ProxyManager does not
+	// read it: requests go direct (no proxy) only when no valid proxy is
+	// left, whatever this value is.
+	MinHealthy int
+
+	// RefreshInterval is not used by the crawler: the proxy pool is
+	// imported from FilePath once, at startup, and proxies added to the file
+	// later are not picked up without a restart.
+	RefreshInterval time.Duration
+}
+
+// StorageConfig selects the storage backend and its connection options.
+// Type discriminates which driver AutoCrawler wires up; the per-driver
+// fields are ignored unless Type selects that driver.
+type StorageConfig struct {
+	// Type is one of "sqlite" (default) or "memory"; "postgres", "mysql",
+	// "etcd", and "s3+sqlite" are recognized by config but fail at
+	// construction (see storage.NewStorage) since none of their client
+	// libraries are vendored in this build and there's no network access
+	// to add them.
+	Type string
+
+	// S3Bucket and S3Prefix configure the archive upload target for
+	// Type=="s3+sqlite". Ignored for every other Type.
+	S3Bucket string
+	S3Prefix string
+
+	// SQLitePath is the database file path used when Type is "sqlite".
+	SQLitePath string
+
+	// DSN is the connection string for "postgres"/"mysql" (and the endpoint
+	// list for "etcd").
+	DSN string
+
+	// Namespace prefixes keys/tables for "etcd" so multiple crawler
+	// deployments can share one cluster without colliding.
+	Namespace string
+
+	// TLSEnabled requests a TLS connection for drivers that support it.
+	TLSEnabled bool
+
+	// MaxOpenConns bounds concurrent writers for multi-worker deployments
+	// against Postgres/MySQL; SQLite always forces this to 1.
+	MaxOpenConns int
 }
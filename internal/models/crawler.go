@@ -10,8 +10,30 @@ import (
 	"time"
 
 	"golang.org/x/sync/semaphore"
+
+	"linkedin-crawler/internal/crawler/ratelimit"
 )
 
+// TokenState tracks the adaptive-selection state for a single token:
+// recent success rate, observed latency, and any active cooldown. It
+// backs weighted token selection and per-status backoff in TokenManager.
+type TokenState struct {
+	SuccessEWMA         float64
+	AvgLatencyMs        int64
+	CooldownUntil       time.Time
+	ConsecutiveFailures int
+}
+
+// ProxyState tracks the adaptive-selection state for a single proxy:
+// observed latency and any active cooldown from connection/TLS faults. It
+// backs weighted proxy selection and backoff in ProxyManager, mirroring
+// TokenState.
+type ProxyState struct {
+	AvgLatencyMs        int64
+	CooldownUntil       time.Time
+	ConsecutiveFailures int
+}
+
 // LinkedInCrawler represents the core LinkedIn crawler
 type LinkedInCrawler struct {
 	Tokens         []string
@@ -33,6 +55,8 @@ type LinkedInCrawler struct {
 	StartTime         time.Time
 	AllTokensFailed   bool
 	TokenMutex        sync.Mutex
+	TokenStats        map[string]*TokenState
+	RateLimitStats    map[string]*ratelimit.TokenState
 	TokensFilePath    string
 	RateLimitedEmails []string
 	RateLimitMutex    sync.Mutex
@@ -42,6 +66,11 @@ type LinkedInCrawler struct {
 	RequestChan       chan struct{}
 	Ctx               context.Context
 	Cancel            context.CancelFunc
+
+	Proxies        []string
+	InvalidProxies map[string]bool
+	ProxyStats     map[string]*ProxyState
+	ProxyMutex     sync.Mutex
 }
 
 // AutoCrawler represents the main orchestrator for the LinkedIn crawler
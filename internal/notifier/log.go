@@ -0,0 +1,18 @@
+package notifier
+
+import (
+	"context"
+
+	"linkedin-crawler/internal/logging"
+)
+
+// LogNotifier reports alerts through internal/logging instead of an
+// external channel. It's meant for local development, where there's no
+// mailbox or webhook endpoint to actually receive alerts.
+type LogNotifier struct{}
+
+// Send logs subject and payload at Info level.
+func (LogNotifier) Send(ctx context.Context, subject string, payload any) error {
+	logging.Logger.Info("🔔 "+subject, "payload", payload)
+	return nil
+}
@@ -0,0 +1,49 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+
+	"linkedin-crawler/internal/models"
+	"linkedin-crawler/internal/notifier/telegram"
+)
+
+// New builds the Notifier selected by params.Kind: "smtp", "webhook",
+// "telegram", or "log". If Kind is empty, or the backend it names has no
+// address configured (SMTPAddr/WebhookURL/TelegramBotToken+ChatID), it
+// falls back to NullNotifier rather than failing the whole crawl over a
+// missing alerting destination.
+//
+// The Notifier returned for "telegram" is Send-only (no Controller, so its
+// command handling is inert) - AutoCrawler builds its own telegram.Bot
+// separately, with itself as Controller, for /stats/ /pause/etc.
+func New(params models.NotifierParams) (Notifier, error) {
+	switch strings.ToLower(params.Kind) {
+	case "smtp":
+		if params.SMTPAddr == "" {
+			return NullNotifier{}, nil
+		}
+		return NewSMTPNotifier(params.SMTPAddr, params.SMTPUser, params.SMTPPass, params.From, params.To)
+
+	case "webhook":
+		if params.WebhookURL == "" {
+			return NullNotifier{}, nil
+		}
+		return NewWebhookNotifier(params.WebhookURL)
+
+	case "telegram":
+		if params.TelegramBotToken == "" || params.TelegramChatID == "" {
+			return NullNotifier{}, nil
+		}
+		return telegram.New(params.TelegramBotToken, params.TelegramChatID, nil)
+
+	case "log":
+		return LogNotifier{}, nil
+
+	case "", "null":
+		return NullNotifier{}, nil
+
+	default:
+		return nil, fmt.Errorf("notifier: unknown kind %q", params.Kind)
+	}
+}
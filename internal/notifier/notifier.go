@@ -0,0 +1,15 @@
+// Package notifier sends operational alerts about a crawl run - a retry
+// loop that stopped making progress, a spike in permanently-failed emails,
+// a batch completing - to whatever backend an operator has configured.
+package notifier
+
+import "context"
+
+// Notifier sends a single alert. subject is a short human-readable title
+// ("retry loop stalled", "batch complete"); payload carries whatever detail
+// the call site has (a count, an error, a summary struct) and each backend
+// formats it however suits it (an SMTP body, a webhook JSON field, a log
+// line).
+type Notifier interface {
+	Send(ctx context.Context, subject string, payload any) error
+}
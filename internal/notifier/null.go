@@ -0,0 +1,13 @@
+package notifier
+
+import "context"
+
+// NullNotifier discards every alert. It's the default backend when nothing
+// is configured, so the orchestrator can always call Notifier.Send without
+// checking whether alerting is actually wired up.
+type NullNotifier struct{}
+
+// Send discards subject and payload and always succeeds.
+func (NullNotifier) Send(ctx context.Context, subject string, payload any) error {
+	return nil
+}
@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+// SMTPNotifier sends each alert as a plain-text email over SMTP, PLAIN-auth
+// against addr the way a small outbound mailer typically does: one
+// short-lived connection per message, no connection pooling or templating.
+type SMTPNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+// NewSMTPNotifier returns an SMTPNotifier authenticating to addr (host:port)
+// as user/pass and sending from "from" to every address in "to".
+func NewSMTPNotifier(addr, user, pass, from string, to []string) (*SMTPNotifier, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("smtp notifier: addr is required")
+	}
+	if from == "" || len(to) == 0 {
+		return nil, fmt.Errorf("smtp notifier: from and at least one to address are required")
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("smtp notifier: invalid addr %q: %w", addr, err)
+	}
+
+	return &SMTPNotifier{
+		addr: addr,
+		auth: smtp.PlainAuth("", user, pass, host),
+		from: from,
+		to:   to,
+	}, nil
+}
+
+// Send emails subject and payload as the message body. The net/smtp
+// dial/auth/send round trip doesn't take a context, so ctx is only checked
+// up front - a send already in flight can't be cancelled.
+func (n *SMTPNotifier) Send(ctx context.Context, subject string, payload any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%v\r\n",
+		n.from, n.to[0], subject, payload)
+
+	if err := smtp.SendMail(n.addr, n.auth, n.from, n.to, []byte(msg)); err != nil {
+		return fmt.Errorf("smtp notifier: %w", err)
+	}
+	return nil
+}
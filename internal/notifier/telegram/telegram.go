@@ -0,0 +1,234 @@
+// Package telegram implements a long-polling Telegram bot that doubles as
+// an alert sink (satisfying notifier.Notifier) and a remote control surface
+// for a running AutoCrawler (via the narrow Controller interface below, so
+// this package doesn't need to import internal/orchestrator).
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-crawler/internal/logging"
+)
+
+const (
+	apiBase           = "https://api.telegram.org/bot"
+	sendTimeout       = 10 * time.Second
+	longPollTimeout   = 30 * time.Second
+	longPollHTTPSlack = 5 * time.Second
+)
+
+// Controller is the subset of AutoCrawler the bot's commands drive.
+// AutoCrawler satisfies this structurally - see orchestrator.New, which
+// passes itself as Controller when constructing a Bot.
+type Controller interface {
+	GetStats() (map[string]int, error)
+	Pause()
+	Resume()
+	IsPaused() bool
+	TokenCounts() (valid int, invalid int, err error)
+	ReloadTokens() error
+}
+
+// Bot pushes alerts to a Telegram chat and, once Run is started, answers
+// /stats, /pause, /resume, /tokens, and /reload_tokens commands sent to
+// that chat. It satisfies notifier.Notifier via Send without importing
+// that package, the same structural-interface approach output.Sink and
+// the database repositories use.
+type Bot struct {
+	token      string
+	chatID     string
+	controller Controller
+	client     *http.Client
+	offset     int64
+}
+
+// New returns a Bot authenticating as token and talking only to chatID.
+// controller may be nil for a Send-only bot (e.g. one built by
+// notifier.New for RetryHandler/StateManager, which only ever call Send);
+// Run refuses commands in that case rather than panicking.
+func New(token, chatID string, controller Controller) (*Bot, error) {
+	if token == "" {
+		return nil, fmt.Errorf("telegram bot: bot token is required")
+	}
+	if chatID == "" {
+		return nil, fmt.Errorf("telegram bot: chat id is required")
+	}
+	return &Bot{
+		token:      token,
+		chatID:     chatID,
+		controller: controller,
+		client:     &http.Client{Timeout: sendTimeout},
+	}, nil
+}
+
+// Send implements notifier.Notifier, formatting subject/payload as a
+// single chat message.
+func (b *Bot) Send(ctx context.Context, subject string, payload any) error {
+	text := subject
+	if payload != nil {
+		if data, err := json.Marshal(payload); err == nil {
+			text = fmt.Sprintf("%s: %s", subject, data)
+		}
+	}
+	return b.call(ctx, "sendMessage", url.Values{
+		"chat_id": {b.chatID},
+		"text":    {text},
+	}, nil)
+}
+
+// Run long-polls getUpdates and dispatches any command it sees from
+// chatID, until ctx is cancelled. It's meant to be started once, in its
+// own goroutine, alongside AutoCrawler.GC/DBGC.
+func (b *Bot) Run(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		updates, err := b.getUpdates(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logging.Logger.Warn("⚠️ telegram bot: không thể getUpdates", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			b.offset = u.UpdateID + 1
+			if u.Message == nil || u.Message.Chat.ID == 0 {
+				continue
+			}
+			if strconv.FormatInt(u.Message.Chat.ID, 10) != b.chatID {
+				continue
+			}
+			reply := b.handleCommand(ctx, strings.TrimSpace(u.Message.Text))
+			if reply != "" {
+				if err := b.Send(ctx, reply, nil); err != nil {
+					logging.Logger.Warn("⚠️ telegram bot: không thể trả lời command", "error", err)
+				}
+			}
+		}
+	}
+}
+
+// handleCommand runs one of /stats, /pause, /resume, /tokens,
+// /reload_tokens and returns the reply text (empty for an unrecognized
+// command, so Run doesn't echo noise back into the chat).
+func (b *Bot) handleCommand(ctx context.Context, text string) string {
+	if b.controller == nil || !strings.HasPrefix(text, "/") {
+		return ""
+	}
+
+	switch strings.Fields(text)[0] {
+	case "/stats":
+		stats, err := b.controller.GetStats()
+		if err != nil {
+			return fmt.Sprintf("failed to read stats: %v", err)
+		}
+		return fmt.Sprintf("total=%d pending=%d success=%d failed=%d",
+			stats["total"], stats["pending"], stats["success_with_data"]+stats["success_without_data"], stats["failed"]+stats["permanent_failed"])
+
+	case "/pause":
+		b.controller.Pause()
+		return "paused"
+
+	case "/resume":
+		b.controller.Resume()
+		return "resumed"
+
+	case "/tokens":
+		valid, invalid, err := b.controller.TokenCounts()
+		if err != nil {
+			return fmt.Sprintf("failed to read token counts: %v", err)
+		}
+		return fmt.Sprintf("valid=%d invalid=%d", valid, invalid)
+
+	case "/reload_tokens":
+		if err := b.controller.ReloadTokens(); err != nil {
+			return fmt.Sprintf("failed to reload tokens: %v", err)
+		}
+		return "tokens reloaded"
+
+	default:
+		return ""
+	}
+}
+
+// update and message mirror just the fields of the Telegram Bot API's
+// getUpdates response this bot needs.
+type update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *message `json:"message"`
+}
+
+type message struct {
+	Text string `json:"text"`
+	Chat struct {
+		ID int64 `json:"id"`
+	} `json:"chat"`
+}
+
+func (b *Bot) getUpdates(ctx context.Context) ([]update, error) {
+	var resp struct {
+		OK     bool     `json:"ok"`
+		Result []update `json:"result"`
+	}
+
+	err := b.call(ctx, "getUpdates", url.Values{
+		"offset":  {strconv.FormatInt(b.offset, 10)},
+		"timeout": {strconv.Itoa(int(longPollTimeout.Seconds()))},
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.OK {
+		return nil, fmt.Errorf("telegram bot: getUpdates returned ok=false")
+	}
+	return resp.Result, nil
+}
+
+// call POSTs form to method and decodes the response body into out (when
+// non-nil). It uses a context-scoped timeout slightly longer than
+// longPollTimeout so getUpdates' own long-poll window isn't cut short by
+// the client's base sendTimeout.
+func (b *Bot) call(ctx context.Context, method string, form url.Values, out any) error {
+	client := b.client
+	if method == "getUpdates" {
+		client = &http.Client{Timeout: longPollTimeout + longPollHTTPSlack}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiBase+b.token+"/"+method, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("telegram bot: failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telegram bot: %s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telegram bot: %s returned %d", method, resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("telegram bot: failed to decode %s response: %w", method, err)
+		}
+	}
+	return nil
+}
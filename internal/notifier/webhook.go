@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// webhookAlert is the JSON body POSTed to a WebhookNotifier's URL.
+type webhookAlert struct {
+	Subject string    `json:"subject"`
+	Payload any       `json:"payload"`
+	Ts      time.Time `json:"ts"`
+}
+
+// WebhookNotifier POSTs each alert as JSON to a configured URL. Unlike
+// output's webhookSink it doesn't retry or spill to disk on failure - an
+// alert that can't be delivered right now is logged and dropped rather than
+// replayed, since by the time a retry would land the condition it reported
+// may no longer hold.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier returns a WebhookNotifier posting to url.
+func NewWebhookNotifier(url string) (*WebhookNotifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook notifier: url is required")
+	}
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: webhookTimeout}}, nil
+}
+
+// Send POSTs subject and payload to the configured URL as JSON.
+func (n *WebhookNotifier) Send(ctx context.Context, subject string, payload any) error {
+	body, err := json.Marshal(webhookAlert{Subject: subject, Payload: payload, Ts: time.Now()})
+	if err != nil {
+		return fmt.Errorf("webhook notifier: failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook notifier: failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook notifier: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook notifier: %s returned %d", n.url, resp.StatusCode)
+	}
+	return nil
+}
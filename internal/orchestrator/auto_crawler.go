@@ -1,46 +1,67 @@
 package orchestrator
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
+	"io"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
+
+	"linkedin-crawler/internal/auth"
+	leaderelection "linkedin-crawler/internal/coordinator"
 	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/events"
+	"linkedin-crawler/internal/logging"
+	"linkedin-crawler/internal/metrics"
 	"linkedin-crawler/internal/models"
+	"linkedin-crawler/internal/notifier/telegram"
+	"linkedin-crawler/internal/orchestrator/checkpoint"
+	"linkedin-crawler/internal/orchestrator/coordinator"
 	"linkedin-crawler/internal/storage"
 	"linkedin-crawler/internal/utils"
 )
 
 // AutoCrawler orchestrates the LinkedIn crawling process
 type AutoCrawler struct {
-	config            models.Config
-	accounts          []models.Account
-	usedAccountIndex  int
-	crawler           *models.LinkedInCrawler
-	crawlerMutex      sync.RWMutex
-	outputFile        string
-	totalEmails       []string
-	processedEmails   int
-	shutdownRequested int32
-
-	logFile      *os.File
-	logWriter    *bufio.Writer
-	logChan      chan string
-	logWaitGroup sync.WaitGroup
+	config           models.Config
+	accounts         []models.Account
+	usedAccountIndex int
+	crawler          *models.LinkedInCrawler
+	crawlerMutex     sync.RWMutex
+	outputFile       string
+	totalEmails      []string
+	processedEmails  int
+
+	// shutdownCtx is cancelled the instant a SIGINT/SIGTERM arrives; see
+	// utils.SetupSignalHandling. Nil until New() installs the handler.
+	shutdownCtx context.Context
+
+	// logger is the structured run log configured via config.LogSink
+	// (JSON-lines file, stdout, or syslog); LogLine routes through it.
+	logger logging.StructuredLogger
+	// logCloser releases logger's underlying resource (e.g. the log file)
+	// on shutdown; nil for sinks that own nothing to close (stdout, syslog).
+	logCloser io.Closer
 
 	// Database storage
 	dbStorage *storage.DBStorage
 
-	// Email tracking maps (kept for compatibility but data is in DB)
-	successEmailsWithData    map[string]struct{}
-	successEmailsWithoutData map[string]struct{}
-	failedEmails             map[string]struct{}
-	permanentFailed          map[string]struct{}
-	emailsMutex              sync.Mutex
+	// Email tracking maps (kept for compatibility but data is in DB). The
+	// value is when the entry was added, so GC can age out entries past
+	// config.EmailMaxStale instead of letting these grow unbounded across a
+	// multi-million-email run.
+	successEmailsWithData    map[string]time.Time
+	successEmailsWithoutData map[string]time.Time
+	failedEmails             map[string]time.Time
+	permanentFailed          map[string]time.Time
+	emailsMutex              sync.RWMutex
+
+	// tokenPoolLastValidated is when GC last re-validated the token pool;
+	// only GC's own goroutine reads or writes it, so it needs no lock.
+	tokenPoolLastValidated time.Time
 
 	// File operation mutex
 	fileOpMutex sync.Mutex
@@ -54,6 +75,81 @@ type AutoCrawler struct {
 	batchProcessor *BatchProcessor
 	retryHandler   *RetryHandler
 	stateManager   *StateManager
+
+	// attemptID identifies this run's leases on the emails table so a
+	// graceful shutdown can release exactly the rows this process holds
+	attemptID string
+
+	// checkpointWAL is the write-ahead log of per-email transitions used to
+	// recover in-flight emails after a hard crash; see orchestrator/checkpoint.
+	checkpointWAL *checkpoint.WAL
+
+	// coord leases out emails/accounts and pools tokens, either against
+	// this process alone (the default) or a shared distributed backend;
+	// see orchestrator/coordinator.
+	coord coordinator.Coordinator
+
+	// elector decides which of several cooperating workers runs
+	// leader-only phases (RetryFailedEmails, SaveStateOnShutdown); see
+	// internal/coordinator.
+	elector leaderelection.LeaderElector
+
+	// loginService logs in a freshly-rotated-in account during DBGC's
+	// account rotation sweep.
+	loginService *auth.LoginService
+
+	// pauseMu guards resumeCh, which gates worker goroutines: nil means
+	// running, non-nil (and open) means paused - workers block receiving
+	// from it until Resume closes it. See Pause/Resume/waitIfPaused.
+	pauseMu  sync.Mutex
+	resumeCh chan struct{}
+
+	// telegramBot pushes operational alerts to a chat and answers /stats,
+	// /pause, /resume, /tokens, /reload_tokens commands; nil unless both
+	// config.Notifier.TelegramBotToken and TelegramChatID are set (Kind is
+	// not checked). When nil, every call site below treats it as a no-op.
+	telegramBot *telegram.Bot
+
+	// eventBus fans crawl-lifecycle events (token invalidated, all tokens
+	// failed, batch complete) out to config.EventSinks; nil when none are
+	// configured, in which case BatchProcessor's QueryService never calls
+	// SetEventBus and every qs.emit becomes a no-op.
+	eventBus *events.Bus
+}
+
+// newRunLogger builds the structured logger LogLine routes through, per
+// config.LogSink: a JSON-lines file (the default), stdout, or syslog. The
+// returned io.Closer is non-nil only for sinks that own a resource to
+// release on shutdown (currently just the file sink).
+func newRunLogger(config models.Config) (logging.StructuredLogger, io.Closer, error) {
+	switch config.LogSink {
+	case "", "file":
+		path := config.LogFilePath
+		if path == "" {
+			path = "crawler.log"
+		}
+		return logging.NewFileLogger(path)
+	case "stdout":
+		l, err := logging.NewStdoutLogger("")
+		return l, nil, err
+	case "syslog":
+		l, err := logging.NewSyslogLogger(config.LogSyslogTag)
+		return l, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unknown log sink %q (want \"file\", \"stdout\", or \"syslog\")", config.LogSink)
+	}
+}
+
+// workerID returns the identity this run's leases, leader-election
+// campaigns, and coordinator heartbeats are tracked under. config.WorkerID
+// lets an operator pin a stable identity across restarts (e.g. one per pod
+// in a StatefulSet, so `cluster status` output stays readable); left empty,
+// each run gets a fresh UUID, preserving the original single-node behavior.
+func workerID(config models.Config) string {
+	if config.WorkerID != "" {
+		return config.WorkerID
+	}
+	return uuid.New().String()
 }
 
 // New creates a new AutoCrawler instance with SQLite support
@@ -61,15 +157,26 @@ func New(config models.Config) (*AutoCrawler, error) {
 	outputFile := "hit.txt"
 
 	// Initialize database
-	dbPath := "crawler.db"
-	if err := storage.InitializeDatabase(dbPath); err != nil {
-		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	dbPath := config.Storage.SQLitePath
+	if dbPath == "" {
+		dbPath = "crawler.db"
+	}
+
+	var initErr error
+	if config.ResetDatabase {
+		logging.Logger.Warn("⚠️ --reset: dropping and recreating schema, crawled progress will be lost")
+		initErr = storage.InitializeDatabaseWithReset(dbPath)
+	} else {
+		initErr = storage.InitializeDatabase(dbPath)
+	}
+	if initErr != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", initErr)
 	}
 
 	dbStorage := storage.GetDBStorage()
 
 	// Import data from files into database
-	fmt.Println("📥 Importing data into database...")
+	logging.Logger.Info("📥 Importing data into database...")
 
 	// Import accounts
 	if err := dbStorage.ImportAccountsFromFile(config.AccountsFilePath); err != nil {
@@ -83,7 +190,12 @@ func New(config models.Config) (*AutoCrawler, error) {
 
 	// Import existing tokens if any
 	if err := dbStorage.ImportTokensFromFile(config.TokensFilePath); err != nil {
-		fmt.Printf("⚠️ No existing tokens to import: %v\n", err)
+		logging.Logger.Warn("⚠️ No existing tokens to import", "error", err)
+	}
+
+	// Import existing proxies if any
+	if err := dbStorage.ImportProxiesFromFile(config.Proxy.FilePath); err != nil {
+		logging.Logger.Warn("⚠️ No existing proxies to import", "error", err)
 	}
 
 	// Get stats from database
@@ -100,20 +212,79 @@ func New(config models.Config) (*AutoCrawler, error) {
 	accountStorage := storage.NewAccountStorage()
 	accountStorage.SetDBStorage(dbStorage)
 
-	// Load data from database
-	accounts, err := accountStorage.LoadAccounts(config.AccountsFilePath)
+	// Load data from database. Startup, before the crawler's cancellable
+	// lifetime context exists.
+	accounts, err := accountStorage.LoadAccounts(context.Background(), config.AccountsFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load accounts: %w", err)
 	}
 
-	emails, err := dbStorage.EmailRepo.GetPendingEmails(0)
+	// Reclaim any leases abandoned by a previous crashed run before counting
+	// what's left to do
+	if reclaimed, err := dbStorage.EmailRepo.ReclaimExpiredLeases(); err == nil && reclaimed > 0 {
+		logging.Logger.Info("♻️ Reclaimed expired email leases from a previous run", "count", reclaimed)
+	}
+
+	checkpointWAL, err := checkpoint.Open(config.CheckpointPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint WAL: %w", err)
+	}
+
+	if config.Resume {
+		requeued, reconciled, err := recoverFromCheckpoint(checkpointWAL, config.CheckpointPath, dbStorage.EmailRepo, config.RequestTimeout*2)
+		if err != nil {
+			logging.Logger.Warn("⚠️ Không thể khôi phục từ checkpoint WAL", "error", err)
+		} else if requeued > 0 || reconciled > 0 {
+			logging.Logger.Info("♻️ Đã khôi phục trạng thái từ checkpoint WAL", "requeued", requeued, "reconciled", reconciled)
+		}
+	}
+
+	coord, err := coordinator.New(config.Coordinator, dbStorage.EmailRepo, dbStorage.AccountRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up coordinator: %w", err)
+	}
+
+	elector, err := leaderelection.New(config.LeaderElection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up leader election: %w", err)
+	}
+
+	emails, err := dbStorage.EmailRepo.GetPendingEmailsPrioritized(0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load emails: %w", err)
 	}
-	// Setup logging
-	logFile, err := os.OpenFile("crawler.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+
+	// /healthz now reflects real readiness instead of an unconditional ok,
+	// for Kubernetes/Docker liveness/readiness probes.
+	metrics.RegisterHealthCheck(func() (bool, string) {
+		if err := dbStorage.DB.GetConn().Ping(); err != nil {
+			return false, fmt.Sprintf("sqlite unreachable: %v", err)
+		}
+		tokenCount, err := dbStorage.TokenRepo.GetValidTokenCount()
+		if err != nil {
+			return false, fmt.Sprintf("failed to count valid tokens: %v", err)
+		}
+		if tokenCount == 0 {
+			return false, "no valid tokens"
+		}
+		accountCount, err := dbStorage.AccountRepo.GetUnusedAccountCount(context.Background())
+		if err != nil {
+			return false, fmt.Sprintf("failed to count unused accounts: %v", err)
+		}
+		if accountCount == 0 {
+			return false, "no unused accounts"
+		}
+		return true, "ok"
+	})
+	// Setup the structured run log LogLine routes through
+	logger, logCloser, err := newRunLogger(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up run logger: %w", err)
+	}
+
+	eventBus, err := events.New(config.EventSinks)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %w", err)
+		return nil, fmt.Errorf("failed to configure event sinks: %w", err)
 	}
 
 	ac := &AutoCrawler{
@@ -123,16 +294,22 @@ func New(config models.Config) (*AutoCrawler, error) {
 		outputFile:       outputFile,
 		totalEmails:      emails,
 		processedEmails:  0,
-		logFile:          logFile,
-		logWriter:        bufio.NewWriter(logFile),
-		logChan:          make(chan string, 1000),
+		logger:           logger,
+		logCloser:        logCloser,
 		dbStorage:        dbStorage,
+		attemptID:        workerID(config),
+		checkpointWAL:    checkpointWAL,
+		coord:            coord,
+		elector:          elector,
+		loginService:     auth.NewLoginService(config.Browser),
+		eventBus:         eventBus,
 
 		// Initialize email tracking maps
-		successEmailsWithData:    make(map[string]struct{}),
-		successEmailsWithoutData: make(map[string]struct{}),
-		failedEmails:             make(map[string]struct{}),
-		permanentFailed:          make(map[string]struct{}),
+		successEmailsWithData:    make(map[string]time.Time),
+		successEmailsWithoutData: make(map[string]time.Time),
+		failedEmails:             make(map[string]time.Time),
+		permanentFailed:          make(map[string]time.Time),
+		tokenPoolLastValidated:   time.Now(),
 
 		// Initialize storage services
 		emailStorage:   emailStorage,
@@ -141,26 +318,34 @@ func New(config models.Config) (*AutoCrawler, error) {
 	}
 
 	// Initialize processing services
-	ac.batchProcessor = NewBatchProcessor(ac)
-	ac.retryHandler = NewRetryHandler(ac)
-	ac.stateManager = NewStateManager(ac)
-
-	// Start logging goroutine
-	ac.logWaitGroup.Add(1)
-	go func() {
-		defer ac.logWaitGroup.Done()
-		for line := range ac.logChan {
-			_, err := ac.logWriter.WriteString(line + "\n")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "⚠️ Lỗi ghi log: %v\n", err)
-			}
-		}
-		ac.logWriter.Flush()
-		ac.logFile.Close()
-	}()
+	batchProcessor, err := NewBatchProcessor(ac)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch processor: %w", err)
+	}
+	ac.batchProcessor = batchProcessor
+
+	retryHandler, err := NewRetryHandler(ac)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create retry handler: %w", err)
+	}
+	ac.retryHandler = retryHandler
+
+	stateManager, err := NewStateManager(ac)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state manager: %w", err)
+	}
+	ac.stateManager = stateManager
 
 	// Setup signal handling
-	utils.SetupSignalHandling(&ac.shutdownRequested, ac.stateManager.SaveStateOnShutdown, config.SleepDuration)
+	// Give in-flight requests ShutdownTimeout to finish and release their
+	// leases normally before the process exits
+	ac.shutdownCtx = utils.SetupSignalHandling(ac.stateManager.SaveStateOnShutdown, config.ShutdownTimeout)
+
+	// Campaign for leadership. The local backend grants it immediately; a
+	// distributed backend blocks here until a predecessor steps down.
+	if _, err := ac.elector.Campaign(ac.shutdownCtx, ac.attemptID); err != nil {
+		return nil, fmt.Errorf("failed to campaign for leadership: %w", err)
+	}
 
 	// Print import stats
 	fmt.Printf("✅ Database initialized successfully:\n")
@@ -169,25 +354,44 @@ func New(config models.Config) (*AutoCrawler, error) {
 	fmt.Printf("   🔑 Total tokens: %d\n", stats["tokens"])
 	fmt.Println(strings.Repeat("=", 80))
 
+	// The Telegram bot is built last, once ac itself can be handed to it as
+	// a telegram.Controller for /stats, /pause, /resume, /tokens, and
+	// /reload_tokens. It stays nil (a no-op) unless both BotToken and
+	// ChatID are configured.
+	if config.Notifier.TelegramBotToken != "" && config.Notifier.TelegramChatID != "" {
+		bot, err := telegram.New(config.Notifier.TelegramBotToken, config.Notifier.TelegramChatID, ac)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up telegram bot: %w", err)
+		}
+		ac.telegramBot = bot
+	}
+
 	return ac, nil
 }
 
 // UpdateEmailStatus updates email status in database
 func (ac *AutoCrawler) UpdateEmailStatus(email string, status database.EmailStatus) error {
-	return ac.dbStorage.EmailRepo.UpdateEmailStatus(email, status)
+	err := ac.dbStorage.EmailRepo.UpdateEmailStatus(email, status)
+	if err == nil {
+		metrics.EmailsProcessedTotal.Inc(string(status))
+	}
+	return err
 }
 
 // UpdateEmailWithProfile updates email with profile data in database
 func (ac *AutoCrawler) UpdateEmailWithProfile(email string, profile models.ProfileData) error {
-	return ac.dbStorage.EmailRepo.UpdateEmailWithProfile(email, profile)
+	err := ac.dbStorage.EmailRepo.UpdateEmailWithProfile(email, profile)
+	if err == nil {
+		metrics.EmailsProcessedTotal.Inc(string(database.EmailStatusSuccessWithData))
+	}
+	return err
 }
 
+// LogLine is a thin compatibility shim for call sites built against the
+// old buffered-file logging API; it now routes the line through logger.Info
+// instead of appending it to crawler.log directly.
 func (ac *AutoCrawler) LogLine(line string) {
-	select {
-	case ac.logChan <- line:
-	default:
-		fmt.Fprintf(os.Stderr, "⚠️ Log channel đầy, bỏ qua log: %s\n", line)
-	}
+	ac.logger.Info(line)
 }
 
 // Run starts the crawling process
@@ -196,17 +400,69 @@ func (ac *AutoCrawler) Run() error {
 		// Close database
 		storage.CloseDatabase()
 
-		if atomic.LoadInt32(&ac.shutdownRequested) == 0 {
-			fmt.Printf("💤 Sleep %v trước khi thoát...\n", ac.config.SleepDuration)
+		if ac.checkpointWAL != nil {
+			if err := ac.checkpointWAL.Close(); err != nil {
+				logging.Logger.Warn("⚠️ Lỗi đóng checkpoint WAL", "error", err)
+			}
+		}
+
+		if ac.batchProcessor != nil && ac.batchProcessor.sinks != nil {
+			if err := ac.batchProcessor.sinks.Close(); err != nil {
+				logging.Logger.Warn("⚠️ Lỗi đóng output sinks", "error", err)
+			}
+		}
+
+		if ac.coord != nil {
+			if err := ac.coord.Close(); err != nil {
+				logging.Logger.Warn("⚠️ Lỗi đóng coordinator", "error", err)
+			}
+		}
+
+		if ac.loginService != nil {
+			ac.loginService.Shutdown()
+		}
+
+		if ac.batchProcessor != nil && ac.batchProcessor.tokenExtractor != nil {
+			ac.batchProcessor.tokenExtractor.Shutdown()
+		}
+
+		if ac.elector != nil {
+			// Resign immediately so a waiting follower fails over right
+			// away instead of waiting out a session timeout.
+			if err := ac.elector.Resign(context.Background()); err != nil {
+				logging.Logger.Warn("⚠️ Lỗi resign leadership", "error", err)
+			}
+			if err := ac.elector.Close(); err != nil {
+				logging.Logger.Warn("⚠️ Lỗi đóng leader elector", "error", err)
+			}
+		}
+
+		if ac.shutdownCtx.Err() == nil {
+			logging.Logger.Info("💤 Sleep trước khi thoát...", "duration", ac.config.SleepDuration)
 			time.Sleep(ac.config.SleepDuration)
 		}
+
+		ac.logger.Info("shutdown", "processed", ac.processedEmails)
+		ac.notifyTelegram("crawl finished", map[string]int{"processed": ac.processedEmails})
+		if ac.eventBus != nil {
+			remaining, _ := ac.dbStorage.EmailRepo.CountRemainingEmails()
+			ac.eventBus.Emit(events.Event{Type: events.BatchComplete, RemainingEmails: remaining, Ts: time.Now()})
+			if err := ac.eventBus.Close(); err != nil {
+				logging.Logger.Warn("⚠️ Lỗi đóng event bus", "error", err)
+			}
+		}
+		if ac.logCloser != nil {
+			if err := ac.logCloser.Close(); err != nil {
+				logging.Logger.Warn("⚠️ Lỗi đóng run logger", "error", err)
+			}
+		}
 	}()
 
 	fmt.Printf("🚀 Bắt đầu Auto LinkedIn Crawler với SQLite\n")
 
 	// Get stats from database
 	stats, _ := ac.dbStorage.EmailRepo.GetEmailStats()
-	accountCount, _ := ac.dbStorage.AccountRepo.GetUnusedAccountCount()
+	accountCount, _ := ac.dbStorage.AccountRepo.GetUnusedAccountCount(ac.shutdownCtx)
 	tokenCount, _ := ac.dbStorage.TokenRepo.GetValidTokenCount()
 
 	fmt.Printf("📊 Database stats:\n")
@@ -216,19 +472,37 @@ func (ac *AutoCrawler) Run() error {
 	fmt.Printf("   🔑 Valid tokens: %d\n", tokenCount)
 	fmt.Println(strings.Repeat("=", 80))
 
+	metrics.ValidTokenCount.Set(float64(tokenCount))
+	if proxyCount, err := ac.dbStorage.ProxyRepo.GetValidProxyCount(); err == nil {
+		metrics.ValidProxyCount.Set(float64(proxyCount))
+	}
+
+	// Background GC keeps the in-memory email maps and token pool bounded
+	// across long runs; it exits on its own once shutdownCtx is cancelled.
+	go ac.GC()
+
+	// Background DBGC sweeps the DB-level token/account/email state on the
+	// same cooperative-shutdown pattern as GC.
+	go ac.DBGC()
+
+	// The Telegram bot's long-polling command loop runs on the same
+	// cooperative-shutdown pattern as GC/DBGC; nil when unconfigured.
+	if ac.telegramBot != nil {
+		go ac.telegramBot.Run(ac.shutdownCtx)
+	}
+
 	// Phase 1 - Xử lý tất cả emails
+	ac.logger.Info("batch start", "total_emails", len(ac.totalEmails))
 	if err := ac.batchProcessor.ProcessAllEmails(); err != nil {
 		return err
 	}
+	ac.logger.Info("batch end", "processed", ac.processedEmails)
 
 	// Phase 2 - Retry emails thất bại
 	if err := ac.retryHandler.RetryFailedEmails(); err != nil {
-		fmt.Printf("⚠️ Lỗi khi retry emails bị thất bại: %v\n", err)
+		logging.Logger.Error("⚠️ Lỗi khi retry emails bị thất bại", "error", err)
 	}
 
-	close(ac.logChan)
-	ac.logWaitGroup.Wait()
-
 	// Print final results
 	ac.printFinalResults()
 
@@ -244,7 +518,7 @@ func (ac *AutoCrawler) printFinalResults() {
 	// Get final stats from database
 	stats, err := ac.dbStorage.EmailRepo.GetEmailStats()
 	if err != nil {
-		fmt.Printf("⚠️ Lỗi khi lấy thống kê: %v\n", err)
+		logging.Logger.Error("⚠️ Lỗi khi lấy thống kê", "error", err)
 		return
 	}
 
@@ -301,6 +575,8 @@ func (ac *AutoCrawler) PrintCurrentStats() {
 	processed := withData + withoutData + permanent
 	fmt.Printf("📊 Stats: ✅%d 📭%d ❌%d 💀%d | Progress: %d/%d (%.1f%%)\n",
 		withData, withoutData, failed, permanent, processed, total, float64(processed)*100/float64(total))
+
+	metrics.QueueDepth.Set(float64(total - processed))
 }
 
 // GetDBStorage returns the database storage
@@ -308,6 +584,92 @@ func (ac *AutoCrawler) GetDBStorage() *storage.DBStorage {
 	return ac.dbStorage
 }
 
+// GetStats returns the same email-status counts PrintCurrentStats prints,
+// for callers (the control-plane API) that need the raw numbers.
+func (ac *AutoCrawler) GetStats() (map[string]int, error) {
+	return ac.dbStorage.EmailRepo.GetEmailStats()
+}
+
+// AddAccount imports a single account, for callers that add accounts one
+// at a time (the control-plane API) rather than via the accounts file.
+func (ac *AutoCrawler) AddAccount(ctx context.Context, account models.Account) error {
+	return ac.dbStorage.AccountRepo.ImportAccounts(ctx, []models.Account{account})
+}
+
+// RevokeToken marks token invalid in the DB immediately, for callers that
+// need to pull one out of rotation without waiting for DBGC's next sweep.
+func (ac *AutoCrawler) RevokeToken(token string) error {
+	return ac.dbStorage.TokenRepo.InvalidateToken(token)
+}
+
+// TokenCounts returns how many tokens are currently valid versus marked
+// invalid in the DB, for callers (the Telegram bot's /tokens command) that
+// want a quick health summary without the full GetStats breakdown.
+func (ac *AutoCrawler) TokenCounts() (valid int, invalid int, err error) {
+	valid, err = ac.dbStorage.TokenRepo.GetValidTokenCount()
+	if err != nil {
+		return 0, 0, err
+	}
+	invalidTokens, err := ac.dbStorage.TokenRepo.GetInvalidTokens()
+	if err != nil {
+		return 0, 0, err
+	}
+	return valid, len(invalidTokens), nil
+}
+
+// ReloadTokens re-reads config.TokensFilePath and adds any new tokens to
+// the pool, without restarting the crawler. AddTokens is idempotent on
+// tokens already known, so this is safe to call repeatedly.
+func (ac *AutoCrawler) ReloadTokens() error {
+	return ac.dbStorage.ImportTokensFromFile(ac.config.TokensFilePath)
+}
+
+// Pause stops worker goroutines from picking up new emails once they
+// finish whatever they're currently processing; it's idempotent.
+func (ac *AutoCrawler) Pause() {
+	ac.pauseMu.Lock()
+	defer ac.pauseMu.Unlock()
+	if ac.resumeCh == nil {
+		ac.resumeCh = make(chan struct{})
+	}
+}
+
+// Resume releases any worker goroutines blocked in waitIfPaused; it's
+// idempotent.
+func (ac *AutoCrawler) Resume() {
+	ac.pauseMu.Lock()
+	defer ac.pauseMu.Unlock()
+	if ac.resumeCh != nil {
+		close(ac.resumeCh)
+		ac.resumeCh = nil
+	}
+}
+
+// IsPaused reports whether Pause has been called without a matching Resume.
+func (ac *AutoCrawler) IsPaused() bool {
+	ac.pauseMu.Lock()
+	defer ac.pauseMu.Unlock()
+	return ac.resumeCh != nil
+}
+
+// waitIfPaused blocks the calling goroutine while the crawler is paused,
+// returning early if ctx is done.
+func (ac *AutoCrawler) waitIfPaused(ctx context.Context) {
+	for {
+		ac.pauseMu.Lock()
+		ch := ac.resumeCh
+		ac.pauseMu.Unlock()
+		if ch == nil {
+			return
+		}
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // Getter methods for service access
 func (ac *AutoCrawler) GetConfig() models.Config {
 	return ac.config
@@ -337,15 +699,15 @@ func (ac *AutoCrawler) GetStorageServices() (*storage.EmailStorage, *storage.Tok
 	return ac.emailStorage, ac.tokenStorage, ac.accountStorage
 }
 
-func (ac *AutoCrawler) GetEmailMaps() (map[string]struct{}, map[string]struct{}, map[string]struct{}, map[string]struct{}) {
-	ac.emailsMutex.Lock()
-	defer ac.emailsMutex.Unlock()
+func (ac *AutoCrawler) GetEmailMaps() (map[string]time.Time, map[string]time.Time, map[string]time.Time, map[string]time.Time) {
+	ac.emailsMutex.RLock()
+	defer ac.emailsMutex.RUnlock()
 
 	// Return copies to prevent external modification
-	withData := make(map[string]struct{})
-	withoutData := make(map[string]struct{})
-	failed := make(map[string]struct{})
-	permanent := make(map[string]struct{})
+	withData := make(map[string]time.Time, len(ac.successEmailsWithData))
+	withoutData := make(map[string]time.Time, len(ac.successEmailsWithoutData))
+	failed := make(map[string]time.Time, len(ac.failedEmails))
+	permanent := make(map[string]time.Time, len(ac.permanentFailed))
 
 	for k, v := range ac.successEmailsWithData {
 		withData[k] = v
@@ -363,7 +725,7 @@ func (ac *AutoCrawler) GetEmailMaps() (map[string]struct{}, map[string]struct{},
 	return withData, withoutData, failed, permanent
 }
 
-func (ac *AutoCrawler) UpdateEmailMaps(withData, withoutData, failed, permanent map[string]struct{}) {
+func (ac *AutoCrawler) UpdateEmailMaps(withData, withoutData, failed, permanent map[string]time.Time) {
 	ac.emailsMutex.Lock()
 	defer ac.emailsMutex.Unlock()
 
@@ -379,18 +741,262 @@ func (ac *AutoCrawler) AddEmailToMap(email string, mapType string) {
 
 	switch mapType {
 	case "withData":
-		ac.successEmailsWithData[email] = struct{}{}
+		ac.successEmailsWithData[email] = time.Now()
 	case "withoutData":
-		ac.successEmailsWithoutData[email] = struct{}{}
+		ac.successEmailsWithoutData[email] = time.Now()
 	case "failed":
-		ac.failedEmails[email] = struct{}{}
+		ac.failedEmails[email] = time.Now()
 	case "permanent":
-		ac.permanentFailed[email] = struct{}{}
+		ac.permanentFailed[email] = time.Now()
+	}
+}
+
+// GC periodically prunes the email tracking maps and re-validates the
+// token pool, so memory stays bounded across a multi-million-email run.
+// It runs until shutdownCtx is cancelled and is meant to be started once,
+// in its own goroutine, alongside Run().
+func (ac *AutoCrawler) GC() {
+	ticker := time.NewTicker(ac.config.GCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ac.shutdownCtx.Done():
+			return
+		case <-ticker.C:
+			ac.gcEmailMaps()
+			ac.gcTokenPool()
+			ac.gcReclaimLeases()
+		}
 	}
 }
 
-func (ac *AutoCrawler) GetShutdownRequested() *int32 {
-	return &ac.shutdownRequested
+// gcReclaimLeases returns any email lease abandoned by a crashed or stalled
+// worker (leased_until elapsed without the owning worker acking it) back to
+// pending, so another worker's next LeaseEmails call can pick it up instead
+// of waiting for it to age out only at startup.
+func (ac *AutoCrawler) gcReclaimLeases() {
+	reclaimed, err := ac.dbStorage.EmailRepo.ReclaimExpiredLeases()
+	if err != nil {
+		logging.Logger.Warn("⚠️ GC: không thể reclaim lease hết hạn", "error", err)
+		return
+	}
+	if reclaimed > 0 {
+		logging.Logger.Info("♻️ GC: reclaimed expired email leases", "count", reclaimed)
+	}
+}
+
+// gcEmailMaps drops email map entries older than config.EmailMaxStale,
+// flushing each one's status to EmailRepo first (best effort - it's
+// normally already there from the write that populated the map) so
+// dropping it from memory loses nothing. It snapshots keys under RLock,
+// does the DB writes unlocked, then takes the write lock only to delete -
+// never holding the map lock across a DB call.
+func (ac *AutoCrawler) gcEmailMaps() {
+	type staleEntry struct {
+		email  string
+		status database.EmailStatus
+	}
+
+	ac.emailsMutex.RLock()
+	var stale []staleEntry
+	cutoff := time.Now().Add(-ac.config.EmailMaxStale)
+	collect := func(m map[string]time.Time, status database.EmailStatus) {
+		for email, addedAt := range m {
+			if addedAt.Before(cutoff) {
+				stale = append(stale, staleEntry{email: email, status: status})
+			}
+		}
+	}
+	collect(ac.successEmailsWithData, database.EmailStatusSuccessWithData)
+	collect(ac.successEmailsWithoutData, database.EmailStatusSuccessNoData)
+	collect(ac.failedEmails, database.EmailStatusFailed)
+	collect(ac.permanentFailed, database.EmailStatusPermanentFailed)
+	ac.emailsMutex.RUnlock()
+
+	if len(stale) == 0 {
+		return
+	}
+
+	for _, e := range stale {
+		if err := ac.dbStorage.EmailRepo.UpdateEmailStatus(e.email, e.status); err != nil {
+			logging.Logger.Warn("⚠️ GC: không thể flush email status xuống DB", "email", e.email, "error", err)
+			continue
+		}
+
+		ac.emailsMutex.Lock()
+		switch e.status {
+		case database.EmailStatusSuccessWithData:
+			delete(ac.successEmailsWithData, e.email)
+		case database.EmailStatusSuccessNoData:
+			delete(ac.successEmailsWithoutData, e.email)
+		case database.EmailStatusFailed:
+			delete(ac.failedEmails, e.email)
+		case database.EmailStatusPermanentFailed:
+			delete(ac.permanentFailed, e.email)
+		}
+		ac.emailsMutex.Unlock()
+	}
+
+	logging.Logger.Info("🧹 GC: đã dọn email map", "count", len(stale))
+}
+
+// gcTokenPool re-validates the token pool once it's older than
+// config.TokenMaxStale, dropping any token that fails validation in the
+// same way RetryFailedEmails already does.
+func (ac *AutoCrawler) gcTokenPool() {
+	if time.Since(ac.tokenPoolLastValidated) < ac.config.TokenMaxStale {
+		return
+	}
+	ac.tokenPoolLastValidated = time.Now()
+
+	tokens, err := ac.tokenStorage.LoadTokensFromFile(ac.config.TokensFilePath)
+	if err != nil || len(tokens) == 0 {
+		return
+	}
+
+	validTokens, err := ac.batchProcessor.validateExistingTokens(tokens)
+	if err != nil {
+		logging.Logger.Warn("⚠️ GC: không thể validate token pool", "error", err)
+		return
+	}
+
+	logging.Logger.Info("🧹 GC: đã validate lại token pool", "before", len(tokens), "after", len(validTokens))
+}
+
+// DBGC periodically sweeps the tokens/accounts/emails tables on
+// config.DBGCInterval: it invalidates tokens that have gone stale,
+// re-probes invalid tokens in case they've recovered, rotates in a fresh
+// account once tokens have been out of rotation too long, and archives old
+// permanent failures. It runs until shutdownCtx is cancelled and is meant
+// to be started once, in its own goroutine, alongside Run().
+func (ac *AutoCrawler) DBGC() {
+	ticker := time.NewTicker(ac.config.DBGCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ac.shutdownCtx.Done():
+			return
+		case <-ticker.C:
+			ac.gcInvalidateStaleTokens()
+			ac.gcRevalidateTokens()
+			ac.gcRotateAccount()
+			ac.gcArchiveOldFailures()
+		}
+	}
+}
+
+// gcInvalidateStaleTokens marks tokens invalid in the DB once they've
+// failed config.TokenFailureThreshold times in a row or gone unused for
+// config.TokenIdleTTL.
+func (ac *AutoCrawler) gcInvalidateStaleTokens() {
+	n, err := ac.dbStorage.TokenRepo.InvalidateStaleTokens(ac.config.TokenFailureThreshold, ac.config.TokenIdleTTL)
+	if err != nil {
+		logging.Logger.Warn("⚠️ DBGC: không thể invalidate stale tokens", "error", err)
+		return
+	}
+	if n > 0 {
+		logging.Logger.Info("🧹 DBGC: đã invalidate token cũ/lỗi trong DB", "count", n)
+		ac.notifyTelegram("tokens invalidated", map[string]int{"count": n})
+	}
+
+	if valid, err := ac.dbStorage.TokenRepo.GetValidTokenCount(); err == nil && valid == 0 {
+		ac.notifyTelegram("all tokens exhausted", nil)
+	}
+}
+
+// notifyTelegram pushes subject/payload to the configured Telegram chat, a
+// no-op when telegramBot is nil (the default, unconfigured case). Errors
+// are logged rather than returned - an undeliverable alert shouldn't stall
+// whichever GC/DBGC sweep triggered it.
+func (ac *AutoCrawler) notifyTelegram(subject string, payload any) {
+	if ac.telegramBot == nil {
+		return
+	}
+	if err := ac.telegramBot.Send(ac.shutdownCtx, subject, payload); err != nil {
+		logging.Logger.Warn("⚠️ không thể gửi thông báo Telegram", "subject", subject, "error", err)
+	}
+}
+
+// gcRevalidateTokens re-probes every token currently marked invalid in the
+// DB and re-enables whichever one still works, the same way gcTokenPool
+// re-checks the file-based pool.
+func (ac *AutoCrawler) gcRevalidateTokens() {
+	invalid, err := ac.dbStorage.TokenRepo.GetInvalidTokens()
+	if err != nil || len(invalid) == 0 {
+		return
+	}
+
+	validAgain, err := ac.batchProcessor.validateExistingTokens(invalid)
+	if err != nil {
+		logging.Logger.Warn("⚠️ DBGC: không thể re-probe invalid tokens", "error", err)
+		return
+	}
+
+	for _, token := range validAgain {
+		if err := ac.dbStorage.TokenRepo.RevalidateToken(token); err != nil {
+			logging.Logger.Warn("⚠️ DBGC: không thể revalidate token", "error", err)
+		}
+	}
+	if len(validAgain) > 0 {
+		logging.Logger.Info("🧹 DBGC: đã revalidate token", "count", len(validAgain))
+	}
+}
+
+// gcRotateAccount rotates in a fresh account once the oldest invalidated
+// token has been out of rotation for longer than config.AccountRotationTTL.
+//
+// The schema has no token-to-account link, so "accounts whose tokens have
+// been invalid for > TTL" can't be tracked literally; this approximates it
+// by watching how long the token pool as a whole has had an invalid,
+// unrecovered entry and pulling in one fresh account once that's past the
+// TTL, rather than per-account.
+func (ac *AutoCrawler) gcRotateAccount() {
+	oldest, err := ac.dbStorage.TokenRepo.GetOldestInvalidation()
+	if err != nil {
+		logging.Logger.Warn("⚠️ DBGC: không thể đọc thời gian invalidate cũ nhất", "error", err)
+		return
+	}
+	if oldest.IsZero() || time.Since(oldest) < ac.config.AccountRotationTTL {
+		return
+	}
+
+	accounts, err := ac.dbStorage.AccountRepo.GetUnusedAccounts(ac.shutdownCtx, 1)
+	if err != nil || len(accounts) == 0 {
+		return
+	}
+	account := accounts[0]
+
+	ac.logger.Info("login", "account", account.Email)
+	if err := ac.loginService.LoginToTeams(ac.shutdownCtx, account); err != nil {
+		logging.Logger.Warn("⚠️ DBGC: không thể login lại account rotate", "email", account.Email, "error", err)
+		return
+	}
+
+	if err := ac.dbStorage.AccountRepo.MarkAccountAsUsed(ac.shutdownCtx, account.Email); err != nil {
+		logging.Logger.Warn("⚠️ DBGC: không thể đánh dấu account đã dùng", "email", account.Email, "error", err)
+	}
+	logging.Logger.Info("🔄 DBGC: đã rotate vào account mới", "email", account.Email)
+}
+
+// gcArchiveOldFailures moves permanent_failed emails older than
+// config.EmailArchiveRetention into emails_archive.
+func (ac *AutoCrawler) gcArchiveOldFailures() {
+	n, err := ac.dbStorage.EmailRepo.ArchivePermanentlyFailed(ac.config.EmailArchiveRetention)
+	if err != nil {
+		logging.Logger.Warn("⚠️ DBGC: không thể archive email permanent_failed", "error", err)
+		return
+	}
+	if n > 0 {
+		logging.Logger.Info("🧹 DBGC: đã archive email permanent_failed", "count", n)
+	}
+}
+
+// ShutdownContext returns the context cancelled when a SIGINT/SIGTERM
+// arrives, so callers can select on ctx.Done() for immediate shutdown.
+func (ac *AutoCrawler) ShutdownContext() context.Context {
+	return ac.shutdownCtx
 }
 
 func (ac *AutoCrawler) GetCrawler() *models.LinkedInCrawler {
@@ -408,3 +1014,76 @@ func (ac *AutoCrawler) SetCrawler(crawler *models.LinkedInCrawler) {
 func (ac *AutoCrawler) GetFileOpMutex() *sync.Mutex {
 	return &ac.fileOpMutex
 }
+
+// GetAttemptID returns the UUID identifying this run's email leases
+func (ac *AutoCrawler) GetAttemptID() string {
+	return ac.attemptID
+}
+
+// GetCheckpoint returns the write-ahead log BatchProcessor records per-email
+// transitions to.
+func (ac *AutoCrawler) GetCheckpoint() *checkpoint.WAL {
+	return ac.checkpointWAL
+}
+
+// GetCoordinator returns the coordinator BatchProcessor leases emails,
+// leases accounts, and pools tokens through.
+func (ac *AutoCrawler) GetCoordinator() coordinator.Coordinator {
+	return ac.coord
+}
+
+// GetEventBus returns the bus BatchProcessor's QueryService emits
+// crawl-lifecycle events to, or nil when config.EventSinks is empty.
+func (ac *AutoCrawler) GetEventBus() *events.Bus {
+	return ac.eventBus
+}
+
+// recoverFromCheckpoint replays the checkpoint WAL and reconciles it against
+// EmailRepo: entries still in_flight after staleAfter are requeued as
+// pending (the worker holding them died without finishing), and done
+// entries are re-applied in case the crash happened between finishing the
+// request and writing the result to SQLite; a done success_with_data entry
+// is requeued as pending instead, since the WAL holds no profile fields. It
+// then compacts the WAL down
+// to one entry per email.
+func recoverFromCheckpoint(wal *checkpoint.WAL, path string, repo *database.EmailRepository, staleAfter time.Duration) (requeued, reconciled int, err error) {
+	entries, err := checkpoint.Replay(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	now := time.Now()
+	for email, entry := range entries {
+		switch entry.State {
+		case checkpoint.StateInFlight:
+			if now.Sub(entry.Timestamp) >= staleAfter {
+				if err := repo.UpdateEmailStatus(email, database.EmailStatusPending); err != nil {
+					logging.Logger.Warn("⚠️ Không thể requeue email từ checkpoint", "email", email, "error", err)
+					continue
+				}
+				requeued++
+			}
+		case checkpoint.StateDone:
+			status := database.EmailStatus(entry.Status)
+			if status == database.EmailStatusSuccessWithData {
+				// The WAL only records the status, not the extracted
+				// profile fields, so re-applying this status without them
+				// would mark the email done with an empty profile.
+				// Requeue it instead so a retry re-fetches and records
+				// the profile data properly.
+				status = database.EmailStatusPending
+			}
+			if err := repo.UpdateEmailStatus(email, status); err != nil {
+				logging.Logger.Warn("⚠️ Không thể reconcile email từ checkpoint", "email", email, "error", err)
+				continue
+			}
+			reconciled++
+		}
+	}
+
+	if err := wal.Compact(entries); err != nil {
+		logging.Logger.Warn("⚠️ Không thể nén checkpoint WAL", "error", err)
+	}
+
+	return requeued, reconciled, nil
+}
@@ -3,6 +3,7 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"math/rand"
 	"os"
 	"strings"
@@ -13,7 +14,15 @@ import (
 	"linkedin-crawler/internal/auth"
 	"linkedin-crawler/internal/crawler"
 	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/logging"
 	"linkedin-crawler/internal/models"
+	"linkedin-crawler/internal/orchestrator/checkpoint"
+	"linkedin-crawler/internal/orchestrator/coordinator"
+	"linkedin-crawler/internal/output"
+	"linkedin-crawler/internal/progress"
+	"linkedin-crawler/internal/storage"
+	"linkedin-crawler/internal/tracing"
+	"linkedin-crawler/internal/utils"
 )
 
 // BatchProcessor handles batch processing of emails
@@ -21,28 +30,73 @@ type BatchProcessor struct {
 	autoCrawler      *AutoCrawler
 	tokenExtractor   *auth.TokenExtractor
 	queryService     *crawler.QueryService
+	backend          crawler.ProfileBackend
 	validatorService *crawler.ValidatorService
+	reporter         progress.Reporter
+	wal              *checkpoint.WAL
+	sinks            *output.FanOut
+	coord            coordinator.Coordinator
+
+	// lineLogger is a structured logger that still reports through
+	// AutoCrawler.LogLine, so it lands in the same buffered log view as
+	// every plain-text status line from this run.
+	lineLogger *slog.Logger
 }
 
 // NewBatchProcessor creates a new BatchProcessor instance
-func NewBatchProcessor(ac *AutoCrawler) *BatchProcessor {
+func NewBatchProcessor(ac *AutoCrawler) (*BatchProcessor, error) {
+	sinks, err := output.New(ac.GetConfig().Outputs, ac.GetCrawler)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure output sinks: %w", err)
+	}
+
+	queryService := crawler.NewQueryService(ac.GetConfig().TokenRateLimit)
+	emailRepo := ac.GetDBStorage().EmailRepo
+	if bus := ac.GetEventBus(); bus != nil {
+		queryService.SetEventBus(bus, func() int {
+			n, _ := emailRepo.CountRemainingEmails()
+			return n
+		})
+	}
+	queryService.SetRetryScheduler(func(email string, delay time.Duration) {
+		if err := emailRepo.ScheduleRetry(email, delay); err != nil {
+			logging.Logger.Warn("⚠️ Không thể lên lịch retry cho email", "email", email, "error", err)
+		}
+	})
+	backend, err := crawler.NewBackend(ac.GetConfig().ProfileBackend, queryService)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure profile backend: %w", err)
+	}
+
 	return &BatchProcessor{
 		autoCrawler:      ac,
 		tokenExtractor:   auth.NewTokenExtractor(),
-		queryService:     crawler.NewQueryService(),
+		queryService:     queryService,
+		backend:          backend,
 		validatorService: crawler.NewValidatorService(),
-	}
+		reporter:         progress.New(ac.GetConfig().ProgressMode, os.Stderr),
+		wal:              ac.GetCheckpoint(),
+		sinks:            sinks,
+		coord:            ac.GetCoordinator(),
+		lineLogger:       logging.NewLineLogger(ac.LogLine),
+	}, nil
 }
 
 // ProcessAllEmails processes all emails with improved token rotation
-func (bp *BatchProcessor) ProcessAllEmails() error {
+func (bp *BatchProcessor) ProcessAllEmails() (err error) {
 	fmt.Println("🔄 Phase 1: Xử lý tất cả emails với token rotation...")
 
+	ctx, rootSpan := tracing.StartSpan(bp.autoCrawler.shutdownCtx, "ProcessAllEmails")
+	defer func() {
+		rootSpan.SetStatus(err)
+		rootSpan.End()
+	}()
+
 	stateManager := bp.autoCrawler.stateManager
 
 	// Main loop - continue until no emails left or no accounts left
 	for stateManager.HasEmailsToProcess() {
-		if atomic.LoadInt32(bp.autoCrawler.GetShutdownRequested()) == 1 {
+		if ctx.Err() != nil {
 			fmt.Println("⚠️ Nhận tín hiệu dừng, thoát khỏi vòng lặp chính")
 			break
 		}
@@ -93,7 +147,7 @@ func (bp *BatchProcessor) ProcessAllEmails() error {
 				fmt.Printf("🔄 Lấy thêm tokens từ accounts (còn %d accounts)\n",
 					len(bp.autoCrawler.GetAccounts())-bp.autoCrawler.GetUsedAccountIndex())
 
-				newTokens, err := bp.getTokensBatch()
+				newTokens, err := bp.getTokensBatch(ctx)
 				if err != nil {
 					fmt.Printf("❌ Lỗi lấy tokens: %v\n", err)
 					if len(validTokens) == 0 {
@@ -120,7 +174,7 @@ func (bp *BatchProcessor) ProcessAllEmails() error {
 			fmt.Printf("▶️ BẮT ĐẦU CRAWLING với %d tokens...\n", len(validTokens))
 			fmt.Printf(strings.Repeat("─", 60) + "\n\n")
 
-			if err := bp.processEmailsWithTokens(validTokens); err != nil {
+			if err := bp.processEmailsWithTokens(ctx, validTokens); err != nil {
 				fmt.Printf("⚠️ Lỗi khi xử lý emails: %v\n", err)
 			}
 
@@ -173,7 +227,7 @@ func (bp *BatchProcessor) validateTokensBatch(tokens []string) ([]string, error)
 }
 
 // getTokensBatch gets a batch of tokens from accounts
-func (bp *BatchProcessor) getTokensBatch() ([]string, error) {
+func (bp *BatchProcessor) getTokensBatch(ctx context.Context) ([]string, error) {
 	var validTokens []string
 	config := bp.autoCrawler.GetConfig()
 	accounts := bp.autoCrawler.GetAccounts()
@@ -204,7 +258,7 @@ func (bp *BatchProcessor) getTokensBatch() ([]string, error) {
 	processedAccounts := 0
 
 	for i := 0; i < len(accountsBatch) && len(validTokens) < tokensNeeded; i += batchSize {
-		if atomic.LoadInt32(bp.autoCrawler.GetShutdownRequested()) == 1 {
+		if ctx.Err() != nil {
 			fmt.Println("⚠️ Nhận tín hiệu dừng trong quá trình lấy tokens")
 			break
 		}
@@ -219,7 +273,7 @@ func (bp *BatchProcessor) getTokensBatch() ([]string, error) {
 			i+1, end, tokensNeeded-len(validTokens))
 
 		// Get tokens from this batch
-		rawTokens := bp.processAccountsBatch(batch)
+		rawTokens := bp.processAccountsBatch(ctx, batch)
 		processedAccounts += len(batch)
 
 		// Validate tokens immediately
@@ -262,7 +316,11 @@ func (bp *BatchProcessor) getTokensBatch() ([]string, error) {
 }
 
 // processAccountsBatch processes a batch of accounts to get tokens
-func (bp *BatchProcessor) processAccountsBatch(accounts []models.Account) []string {
+func (bp *BatchProcessor) processAccountsBatch(ctx context.Context, accounts []models.Account) []string {
+	batchCtx, span := tracing.StartSpan(ctx, "token_acquisition_batch")
+	defer span.End()
+	span.SetAttributes("accounts.count", len(accounts))
+
 	config := bp.autoCrawler.GetConfig()
 	results := bp.tokenExtractor.ExtractTokensBatch(accounts, config.AccountsFilePath)
 
@@ -270,27 +328,50 @@ func (bp *BatchProcessor) processAccountsBatch(accounts []models.Account) []stri
 	for _, result := range results {
 		if result.Error == nil && result.Token != "" {
 			validTokens = append(validTokens, result.Token)
+			logging.Logger.Info("token acquired", "account", result.Account.Email, "token_id", utils.TokenFingerprint(result.Token))
+			if bp.coord != nil {
+				if err := bp.coord.PublishToken(batchCtx, result.Token, coordinator.TokenMeta{
+					WorkerID:    bp.autoCrawler.GetAttemptID(),
+					PublishedAt: time.Now(),
+				}); err != nil {
+					logging.Logger.Warn("⚠️ Không thể publish token tới coordinator", "error", err)
+				}
+			}
 		}
 	}
+	span.SetAttributes("tokens.acquired", len(validTokens))
 	return validTokens
 }
 
 // processEmailsWithTokens processes emails with the given tokens
-func (bp *BatchProcessor) processEmailsWithTokens(tokens []string) error {
+func (bp *BatchProcessor) processEmailsWithTokens(ctx context.Context, tokens []string) error {
 	if err := bp.initializeCrawler(tokens); err != nil {
 		return fmt.Errorf("failed to initialize crawler: %w", err)
 	}
 	defer func() {
 		crawlerInstance := bp.autoCrawler.GetCrawler()
 		if crawlerInstance != nil {
+			bp.persistTokenStats(crawlerInstance)
 			crawler.Close(crawlerInstance) // Use function instead of method
 			bp.autoCrawler.SetCrawler(nil)
 		}
 	}()
 
-	// Get remaining emails (DO NOT reset to 0)
-	stateManager := bp.autoCrawler.stateManager
-	remainingEmails := stateManager.GetRemainingEmails()
+	// Lease the next batch of remaining emails under this run's attempt ID
+	// so a concurrently-running worker sharing the same DB won't also pick
+	// them up (DO NOT reset progress to 0). This goes straight to
+	// EmailRepo rather than through bp.coord.LeaseEmails because
+	// stateManager's graceful-shutdown path releases leases by this
+	// specific attemptID (see ReleaseAttemptLeases); coord.LeaseEmails
+	// mints a fresh LeaseID per call, which is the right shape for a
+	// distributed backend but would leave this run's attemptID-tagged
+	// release path with nothing to release.
+	config := bp.autoCrawler.GetConfig()
+	dbStorage := bp.autoCrawler.GetDBStorage()
+	remainingEmails, err := dbStorage.EmailRepo.LeaseEmails(bp.autoCrawler.GetAttemptID(), 0, config.LeaseDuration)
+	if err != nil {
+		return fmt.Errorf("failed to lease emails: %w", err)
+	}
 
 	if len(remainingEmails) == 0 {
 		fmt.Println("✅ Không còn emails nào cần xử lý")
@@ -299,7 +380,7 @@ func (bp *BatchProcessor) processEmailsWithTokens(tokens []string) error {
 
 	fmt.Printf("🎯 Tiếp tục crawl %d emails còn lại với %d tokens...\n", len(remainingEmails), len(tokens))
 
-	processedCount, err := bp.crawlWithCurrentTokens(remainingEmails)
+	processedCount, err := bp.crawlWithCurrentTokens(ctx, remainingEmails)
 
 	fmt.Printf("✅ Đã xử lý %d emails trong batch này\n", processedCount)
 	return err
@@ -320,14 +401,93 @@ func (bp *BatchProcessor) initializeCrawler(tokens []string) error {
 	newCrawler.TokensFilePath = config.TokensFilePath
 	newCrawler.RateLimitedEmails = []string{}
 
+	// Resume adaptive token weighting/cooldowns from the last run instead of
+	// starting every token back at a clean slate
+	dbStorage := bp.autoCrawler.GetDBStorage()
+	if persisted, err := dbStorage.TokenRepo.GetTokenStatsMap(tokens); err != nil {
+		logging.Logger.Warn("⚠️ Không thể load token stats đã lưu", "error", err)
+	} else {
+		for token, s := range persisted {
+			newCrawler.TokenStats[token] = &models.TokenState{
+				SuccessEWMA:   s.SuccessEWMA,
+				AvgLatencyMs:  s.AvgLatencyMs,
+				CooldownUntil: s.CooldownUntil,
+			}
+		}
+	}
+
+	// Load the proxy pool alongside the tokens, resuming each proxy's
+	// adaptive state the same way
+	proxies, err := dbStorage.ProxyRepo.GetValidProxies()
+	if err != nil {
+		logging.Logger.Warn("⚠️ Không thể load danh sách proxy", "error", err)
+	} else {
+		newCrawler.Proxies = proxies
+		if len(proxies) < config.Proxy.MinHealthy {
+			logging.Logger.Warn("⚠️ Không đủ proxy khả dụng, request sẽ đi direct khi cần",
+				"available", len(proxies), "min_healthy", config.Proxy.MinHealthy)
+		}
+
+		if persisted, err := dbStorage.ProxyRepo.GetProxyStatsMap(proxies); err != nil {
+			logging.Logger.Warn("⚠️ Không thể load proxy stats đã lưu", "error", err)
+		} else {
+			for proxyURL, s := range persisted {
+				newCrawler.ProxyStats[proxyURL] = &models.ProxyState{
+					AvgLatencyMs:  s.AvgLatencyMs,
+					CooldownUntil: s.CooldownUntil,
+				}
+			}
+		}
+	}
+
 	bp.autoCrawler.SetCrawler(newCrawler)
 
 	fmt.Printf("✅ Crawler đã sẵn sàng với %d tokens\n", len(tokens))
 	return nil
 }
 
+// persistTokenStats saves the crawler's adaptive token and proxy state back
+// to their tables so the next batch (or the next process, after a restart)
+// can resume weighting/cooldowns instead of treating everything as fresh
+func (bp *BatchProcessor) persistTokenStats(lc *models.LinkedInCrawler) {
+	dbStorage := bp.autoCrawler.GetDBStorage()
+
+	lc.TokenMutex.Lock()
+	tokenSnapshot := make(map[string]database.TokenStats, len(lc.TokenStats))
+	for token, s := range lc.TokenStats {
+		tokenSnapshot[token] = database.TokenStats{
+			SuccessEWMA:   s.SuccessEWMA,
+			AvgLatencyMs:  s.AvgLatencyMs,
+			CooldownUntil: s.CooldownUntil,
+		}
+	}
+	lc.TokenMutex.Unlock()
+
+	for token, stats := range tokenSnapshot {
+		if err := dbStorage.TokenRepo.UpdateTokenStats(token, stats); err != nil {
+			logging.Logger.Warn("⚠️ Không thể lưu token stats cho token", "error", err)
+		}
+	}
+
+	lc.ProxyMutex.Lock()
+	proxySnapshot := make(map[string]database.ProxyStats, len(lc.ProxyStats))
+	for proxyURL, s := range lc.ProxyStats {
+		proxySnapshot[proxyURL] = database.ProxyStats{
+			AvgLatencyMs:  s.AvgLatencyMs,
+			CooldownUntil: s.CooldownUntil,
+		}
+	}
+	lc.ProxyMutex.Unlock()
+
+	for proxyURL, stats := range proxySnapshot {
+		if err := dbStorage.ProxyRepo.UpdateProxyStats(proxyURL, stats); err != nil {
+			logging.Logger.Warn("⚠️ Không thể lưu proxy stats", "error", err)
+		}
+	}
+}
+
 // crawlWithCurrentTokens crawls emails with current tokens
-func (bp *BatchProcessor) crawlWithCurrentTokens(emails []string) (int, error) {
+func (bp *BatchProcessor) crawlWithCurrentTokens(parentCtx context.Context, emails []string) (int, error) {
 	if len(emails) == 0 {
 		return 0, nil
 	}
@@ -340,7 +500,7 @@ func (bp *BatchProcessor) crawlWithCurrentTokens(emails []string) (int, error) {
 	fmt.Printf("📊 Tiến độ tổng thể: Đã hoàn thành %d/%d emails (%.1f%%)\n",
 		alreadyProcessed, totalOriginalEmails, float64(alreadyProcessed)*100/float64(totalOriginalEmails))
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(parentCtx)
 	defer cancel()
 
 	// Reset stats cho batch này
@@ -353,25 +513,28 @@ func (bp *BatchProcessor) crawlWithCurrentTokens(emails []string) (int, error) {
 		crawlerInstance.AllTokensFailed = false
 	}
 
+	bp.reporter.SetTotal(len(emails))
+
 	emailCh := make(chan string, 100)
 	done := make(chan struct{})
 
 	// Status ticker
+	const checkpointEveryTicks = 30 // compact the checkpoint WAL every ~30s
 	statusTicker := time.NewTicker(1 * time.Second)
 	go func() {
 		defer statusTicker.Stop()
-		lastDisplay := ""
-		isFirstDisplay := true
 
+		ticks := 0
 		for {
 			select {
 			case <-ctx.Done():
-				if !isFirstDisplay {
-					fmt.Fprintf(os.Stderr, "\r\033[A\033[K\033[K\r")
-				}
-				fmt.Println()
 				return
 			case <-statusTicker.C:
+				ticks++
+				if ticks%checkpointEveryTicks == 0 {
+					bp.Checkpoint()
+				}
+
 				// Check token status
 				allTokensFailed := false
 				validTokenCount := 0
@@ -380,6 +543,7 @@ func (bp *BatchProcessor) crawlWithCurrentTokens(emails []string) (int, error) {
 				batchSuccess := int32(0)
 				batchFailed := int32(0)
 				activeReqs := int32(0)
+				var tokenStats []progress.TokenStat
 
 				crawlerInstance := bp.autoCrawler.GetCrawler()
 				if crawlerInstance != nil {
@@ -396,58 +560,52 @@ func (bp *BatchProcessor) crawlWithCurrentTokens(emails []string) (int, error) {
 							validTokenCount++
 						}
 					}
+
+					tokenStats = make([]progress.TokenStat, 0, len(crawlerInstance.Tokens))
+					for _, token := range crawlerInstance.Tokens {
+						rl, successRate := bp.queryService.TokenStats(crawlerInstance, token)
+						tokenStats = append(tokenStats, progress.TokenStat{
+							Fingerprint:  utils.TokenFingerprint(token),
+							SuccessRate:  successRate,
+							BreakerState: rl.State,
+							BackoffUntil: rl.BackoffUntil,
+							Invalid:      rl.Invalid,
+						})
+					}
 				}
 
 				// If tokens failed, stop crawling to get new tokens
 				if allTokensFailed {
-					fmt.Printf("\n❌ Tất cả tokens đã hết hiệu lực, cần lấy tokens mới\n")
+					logging.Logger.Warn("❌ Tất cả tokens đã hết hiệu lực, cần lấy tokens mới")
 					cancel() // Stop current crawling
 					return
 				}
 
-				// Display progress
-				withDataCount, withoutDataCount, failedCount, permanentFailedCount := bp.autoCrawler.GetEmailMaps()
+				withDataCount, withoutDataCount, _, _ := bp.autoCrawler.GetEmailMaps()
 				totalProcessedGlobal := len(withDataCount) + len(withoutDataCount)
 
-				batchPercent := 0.0
-				if len(emails) > 0 {
-					batchPercent = float64(batchProcessed) * 100 / float64(len(emails))
-				}
-
-				totalPercent := float64(totalProcessedGlobal) * 100 / float64(totalOriginalEmails)
-
-				// Progress bar
-				barWidth := 25
-				completedWidth := int(float64(barWidth) * batchPercent / 100)
-				bar := "["
-				for i := 0; i < barWidth; i++ {
-					if i < completedWidth {
-						bar += "█"
-					} else if i == completedWidth && batchPercent > 0 && completedWidth < barWidth {
-						bar += "▓"
-					} else {
-						bar += "░"
+				bp.reporter.Update(progress.Snapshot{
+					Processed:   batchProcessed,
+					Success:     batchSuccess,
+					Failed:      batchFailed,
+					Active:      activeReqs,
+					ValidTokens: validTokenCount,
+					TotalTokens: totalTokens,
+					GlobalDone:  totalProcessedGlobal,
+					GlobalTotal: totalOriginalEmails,
+					Tokens:      tokenStats,
+				})
+
+				if bp.coord != nil {
+					if err := bp.coord.Heartbeat(ctx, coordinator.WorkerStatus{
+						WorkerID:  bp.autoCrawler.GetAttemptID(),
+						Processed: int64(batchProcessed),
+						Success:   int64(batchSuccess),
+						Failed:    int64(batchFailed),
+					}); err != nil {
+						logging.Logger.Warn("⚠️ Không thể gửi heartbeat tới coordinator", "error", err)
 					}
 				}
-				bar += "]"
-
-				line1 := fmt.Sprintf("🔄 Batch: %s %.1f%% (%d/%d) | Success: %d | Failed: %d | Active: %d | Tokens: %d/%d",
-					bar, batchPercent, batchProcessed, len(emails), batchSuccess, batchFailed, activeReqs, validTokenCount, totalTokens)
-
-				line2 := fmt.Sprintf("📊 Total: %.1f%% (%d/%d) | ✅Data: %d | 📭NoData: %d | ❌Failed: %d | 💀Permanent: %d",
-					totalPercent, totalProcessedGlobal, totalOriginalEmails,
-					len(withDataCount), len(withoutDataCount), len(failedCount), len(permanentFailedCount))
-
-				newDisplay := line1 + "\n" + line2
-
-				if newDisplay != lastDisplay {
-					if !isFirstDisplay {
-						fmt.Fprintf(os.Stderr, "\r\033[A\033[K\033[K")
-					}
-					fmt.Fprintf(os.Stderr, "%s\n%s", line1, line2)
-					lastDisplay = newDisplay
-					isFirstDisplay = false
-				}
 			}
 		}
 	}()
@@ -482,7 +640,12 @@ func (bp *BatchProcessor) crawlWithCurrentTokens(emails []string) (int, error) {
 					default:
 					}
 
-					if atomic.LoadInt32(bp.autoCrawler.GetShutdownRequested()) == 1 {
+					if bp.autoCrawler.shutdownCtx.Err() != nil {
+						return
+					}
+
+					bp.autoCrawler.waitIfPaused(ctx)
+					if ctx.Err() != nil {
 						return
 					}
 
@@ -491,13 +654,14 @@ func (bp *BatchProcessor) crawlWithCurrentTokens(emails []string) (int, error) {
 					if crawlerInstance != nil {
 						allTokensFailed := crawlerInstance.AllTokensFailed
 						if allTokensFailed {
-							fmt.Printf("\n❌ Tokens hết hiệu lực trong quá trình crawl, dừng worker\n")
+							logging.Logger.Warn("❌ Tokens hết hiệu lực trong quá trình crawl, dừng worker")
 							cancel()
 							return
 						}
 
 						atomic.AddInt32(&crawlerInstance.Stats.Processed, 1)
-						success := bp.retryEmailWithNewLogic(email, 5)
+						bp.reporter.Increment()
+						success := bp.retryEmailWithNewLogic(ctx, email, 5)
 
 						if !success {
 							bp.autoCrawler.LogLine(fmt.Sprintf("💾 Email %s thất bại sau 5 lần retry - giữ lại trong file", email))
@@ -513,8 +677,7 @@ func (bp *BatchProcessor) crawlWithCurrentTokens(emails []string) (int, error) {
 	select {
 	case <-done:
 		statusTicker.Stop()
-		fmt.Fprintf(os.Stderr, "\r\033[A\033[K\033[K\r")
-		fmt.Println()
+		bp.reporter.Finish()
 
 		processed := int32(0)
 		success := int32(0)
@@ -535,8 +698,7 @@ func (bp *BatchProcessor) crawlWithCurrentTokens(emails []string) (int, error) {
 
 	case <-ctx.Done():
 		statusTicker.Stop()
-		fmt.Fprintf(os.Stderr, "\r\033[A\033[K\033[K\r")
-		fmt.Println()
+		bp.reporter.Finish()
 
 		bp.autoCrawler.stateManager.UpdateEmailsFile()
 
@@ -546,7 +708,7 @@ func (bp *BatchProcessor) crawlWithCurrentTokens(emails []string) (int, error) {
 			processed = atomic.LoadInt32(&crawlerInstance.Stats.Processed)
 		}
 
-		if atomic.LoadInt32(bp.autoCrawler.GetShutdownRequested()) == 1 {
+		if bp.autoCrawler.shutdownCtx.Err() != nil {
 			fmt.Printf("⚠️ Crawling bị dừng do Ctrl+C: Đã xử lý %d emails\n", processed)
 		} else {
 			fmt.Printf("🔄 Crawling tạm dừng để lấy tokens mới: Đã xử lý %d emails\n", processed)
@@ -555,89 +717,63 @@ func (bp *BatchProcessor) crawlWithCurrentTokens(emails []string) (int, error) {
 	}
 }
 
+// Checkpoint compacts the checkpoint WAL down to one entry per email, so
+// its size stays bounded across a long-running batch rather than growing
+// with every attempt. Invoked periodically by the status ticker.
+func (bp *BatchProcessor) Checkpoint() {
+	if bp.wal == nil {
+		return
+	}
+	entries, err := checkpoint.Replay(bp.autoCrawler.GetConfig().CheckpointPath)
+	if err != nil {
+		logging.Logger.Warn("⚠️ Không thể đọc checkpoint WAL", "error", err)
+		return
+	}
+	if err := bp.wal.Compact(entries); err != nil {
+		logging.Logger.Warn("⚠️ Không thể nén checkpoint WAL", "error", err)
+	}
+}
+
+// recordInFlight appends an in_flight checkpoint entry for email, best
+// effort: QueryProfileWithRetryLogic doesn't expose which token it ended up
+// using, so tokenID is left blank.
+func (bp *BatchProcessor) recordInFlight(email string, attempt int) {
+	if bp.wal == nil {
+		return
+	}
+	if err := bp.wal.RecordInFlight(email, "", attempt); err != nil {
+		logging.Logger.Warn("⚠️ Không thể ghi checkpoint WAL", "email", email, "error", err)
+	}
+}
+
+// recordDone appends a terminal checkpoint entry for email.
+func (bp *BatchProcessor) recordDone(email string, status database.EmailStatus) {
+	if bp.wal == nil {
+		return
+	}
+	if err := bp.wal.RecordDone(email, string(status)); err != nil {
+		logging.Logger.Warn("⚠️ Không thể ghi checkpoint WAL", "email", email, "error", err)
+	}
+}
+
 // Updated internal/orchestrator/batch_processor.go - Key method
-func (bp *BatchProcessor) retryEmailWithNewLogic(email string, maxRetries int) bool {
+func (bp *BatchProcessor) retryEmailWithNewLogic(ctx context.Context, email string, maxRetries int) bool {
 	config := bp.autoCrawler.GetConfig()
 	crawlerInstance := bp.autoCrawler.GetCrawler()
 	dbStorage := bp.autoCrawler.GetDBStorage()
 
+	emailHash := utils.EmailHash(email)
+	var prevAttempt *tracing.Link
+
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if atomic.LoadInt32(bp.autoCrawler.GetShutdownRequested()) == 1 {
+		if ctx.Err() != nil {
 			return false
 		}
 
-		if crawlerInstance != nil {
-			allTokensFailed := crawlerInstance.AllTokensFailed
-			if allTokensFailed {
-				bp.autoCrawler.LogLine(fmt.Sprintf("❌ Tất cả tokens đã bị lỗi, dừng retry cho email: %s", email))
-				return false
-			}
-
-			reqCtx, reqCancel := context.WithTimeout(context.Background(), config.RequestTimeout)
-			hasProfile, body, statusCode, _ := bp.queryService.QueryProfileWithRetryLogic(crawlerInstance, reqCtx, email)
-			reqCancel()
-
-			// Log attempt
-			snippet := ""
-			if len(body) > 200 {
-				snippet = string(body[:200]) + "..."
-			} else {
-				snippet = string(body)
-			}
-
-			bp.autoCrawler.LogLine(fmt.Sprintf("Retry %d/%d - Email: %s | Status: %d | Response: %s",
-				attempt, maxRetries, email, statusCode, snippet))
-
-			// Distinguish between data and no data
-			if statusCode == 200 {
-				if hasProfile {
-					// Check if there's actual profile data
-					profileExtractor := crawler.NewProfileExtractor()
-					profile, parseErr := profileExtractor.ExtractProfileData(body)
-					if parseErr == nil && profile.User != "" && profile.User != "null" && profile.User != "{}" {
-						// HAS LINKEDIN INFO - Update database
-						if err := dbStorage.EmailRepo.UpdateEmailWithProfile(email, profile); err != nil {
-							bp.autoCrawler.LogLine(fmt.Sprintf("⚠️ Lỗi update database: %v", err))
-						}
-
-						bp.autoCrawler.LogLine(fmt.Sprintf("✅ Email có thông tin LinkedIn: %s | User: %s | URL: %s",
-							email, profile.User, profile.LinkedInURL))
-
-						// Write to hit.txt file
-						profileExtractor.WriteProfileToFile(crawlerInstance, email, profile)
-						atomic.AddInt32(&crawlerInstance.Stats.Success, 1)
-					} else {
-						// NO LINKEDIN INFO - Update database
-						if err := dbStorage.EmailRepo.UpdateEmailStatus(email, database.EmailStatusSuccessNoData); err != nil {
-							bp.autoCrawler.LogLine(fmt.Sprintf("⚠️ Lỗi update database: %v", err))
-						}
-
-						bp.autoCrawler.LogLine(fmt.Sprintf("📭 Email không có thông tin LinkedIn: %s", email))
-						atomic.AddInt32(&crawlerInstance.Stats.Success, 1)
-					}
-				} else {
-					// NO LINKEDIN INFO - Update database
-					if err := dbStorage.EmailRepo.UpdateEmailStatus(email, database.EmailStatusSuccessNoData); err != nil {
-						bp.autoCrawler.LogLine(fmt.Sprintf("⚠️ Lỗi update database: %v", err))
-					}
-
-					bp.autoCrawler.LogLine(fmt.Sprintf("📭 Email không có thông tin LinkedIn: %s", email))
-					atomic.AddInt32(&crawlerInstance.Stats.Success, 1)
-				}
-
-				return true
-			}
-
-			// If not last attempt and not successful, wait before retry
-			if attempt < maxRetries {
-				// Random delay between 100-500ms
-				r := rand.New(rand.NewSource(time.Now().UnixNano()))
-				delayMs := 200 + r.Intn(401)
-				delay := time.Duration(delayMs) * time.Millisecond
-
-				bp.autoCrawler.LogLine(fmt.Sprintf("⏳ Chờ %dms trước khi retry lần %d cho email: %s", delayMs, attempt+1, email))
-				time.Sleep(delay)
-			}
+		done, success, link := bp.attemptOnce(ctx, email, emailHash, attempt, maxRetries, prevAttempt, config, crawlerInstance, dbStorage)
+		prevAttempt = link
+		if done {
+			return success
 		}
 	}
 
@@ -645,6 +781,8 @@ func (bp *BatchProcessor) retryEmailWithNewLogic(email string, maxRetries int) b
 	if err := dbStorage.EmailRepo.UpdateEmailStatus(email, database.EmailStatusFailed); err != nil {
 		bp.autoCrawler.LogLine(fmt.Sprintf("⚠️ Lỗi update database: %v", err))
 	}
+	bp.recordDone(email, database.EmailStatusFailed)
+	bp.sinks.Write(email, models.ProfileData{}, database.EmailStatusFailed)
 
 	// Increment retry count in database
 	if err := dbStorage.EmailRepo.IncrementRetryCount(email, "Failed after max retries"); err != nil {
@@ -659,3 +797,120 @@ func (bp *BatchProcessor) retryEmailWithNewLogic(email string, maxRetries int) b
 	}
 	return false
 }
+
+// attemptOnce runs a single retry attempt for email as its own
+// "email_retry_attempt" span, linked to the previous attempt's span (if
+// any) so a trace viewer can follow an email across its whole retry chain.
+// done reports whether retryEmailWithNewLogic should stop looping (either a
+// terminal result was reached, or there's nothing left to try); when done is
+// true, success is the value retryEmailWithNewLogic should return.
+func (bp *BatchProcessor) attemptOnce(ctx context.Context, email, emailHash string, attempt, maxRetries int, prevAttempt *tracing.Link, config models.Config, crawlerInstance *models.LinkedInCrawler, dbStorage *storage.DBStorage) (done bool, success bool, link *tracing.Link) {
+	attemptCtx, span := tracing.StartSpan(ctx, "email_retry_attempt")
+	defer span.End()
+	attemptCtx = logging.WithFields(logging.IntoContext(attemptCtx, bp.lineLogger), "email", email, "retry_attempt", attempt)
+	span.SetAttributes("email.hash", emailHash, "attempt", attempt)
+	if prevAttempt != nil {
+		span.AddLink(*prevAttempt)
+	}
+	attemptLink := span.Link()
+
+	if crawlerInstance == nil {
+		return false, false, &attemptLink
+	}
+
+	if crawlerInstance.AllTokensFailed {
+		bp.autoCrawler.LogLine(fmt.Sprintf("❌ Tất cả tokens đã bị lỗi, dừng retry cho email: %s", email))
+		span.SetStatus(fmt.Errorf("all tokens failed"))
+		return true, false, &attemptLink
+	}
+
+	bp.recordInFlight(email, attempt)
+
+	reqCtx, reqCancel := context.WithTimeout(attemptCtx, config.RequestTimeout)
+	requestStart := time.Now()
+	var result crawler.ProfileResult
+	select {
+	case result = <-bp.backend.FetchProfile(reqCtx, crawlerInstance, email):
+	case <-reqCtx.Done():
+		result = crawler.ProfileResult{Err: reqCtx.Err()}
+	}
+	reqCancel()
+	latencyMs := time.Since(requestStart).Milliseconds()
+	hasProfile, body, statusCode, token, err := result.HasProfile, result.Body, result.StatusCode, result.Token, result.Err
+
+	bp.autoCrawler.logger.Info("email result",
+		"email", email, "token_id", utils.TokenFingerprint(token),
+		"attempt", attempt, "latency_ms", latencyMs, "status", statusCode)
+
+	span.SetAttributes("token.id", utils.TokenFingerprint(token), "http.status", statusCode, "linkedin.has_profile", hasProfile)
+	if err != nil {
+		span.SetStatus(err)
+	}
+
+	// Log attempt
+	snippet := ""
+	if len(body) > 200 {
+		snippet = string(body[:200]) + "..."
+	} else {
+		snippet = string(body)
+	}
+
+	logging.FromContext(attemptCtx).With("token_id", utils.TokenFingerprint(token)).Info(
+		"retry attempt", "max_retries", maxRetries, "status", statusCode, "response", snippet)
+
+	// Distinguish between data and no data
+	if statusCode == 200 {
+		if hasProfile {
+			// Backend already parsed the profile out of body for us
+			profile := result.Profile
+			if profile.User != "" && profile.User != "null" && profile.User != "{}" {
+				// HAS LINKEDIN INFO - Update database
+				if err := dbStorage.EmailRepo.UpdateEmailWithProfile(email, profile); err != nil {
+					bp.autoCrawler.LogLine(fmt.Sprintf("⚠️ Lỗi update database: %v", err))
+				}
+
+				bp.autoCrawler.LogLine(fmt.Sprintf("✅ Email có thông tin LinkedIn: %s | User: %s | URL: %s",
+					email, profile.User, profile.LinkedInURL))
+
+				atomic.AddInt32(&crawlerInstance.Stats.Success, 1)
+				bp.recordDone(email, database.EmailStatusSuccessWithData)
+				bp.sinks.Write(email, profile, database.EmailStatusSuccessWithData)
+			} else {
+				// NO LINKEDIN INFO - Update database
+				if err := dbStorage.EmailRepo.UpdateEmailStatus(email, database.EmailStatusSuccessNoData); err != nil {
+					bp.autoCrawler.LogLine(fmt.Sprintf("⚠️ Lỗi update database: %v", err))
+				}
+
+				bp.autoCrawler.LogLine(fmt.Sprintf("📭 Email không có thông tin LinkedIn: %s", email))
+				atomic.AddInt32(&crawlerInstance.Stats.Success, 1)
+				bp.recordDone(email, database.EmailStatusSuccessNoData)
+				bp.sinks.Write(email, models.ProfileData{}, database.EmailStatusSuccessNoData)
+			}
+		} else {
+			// NO LINKEDIN INFO - Update database
+			if err := dbStorage.EmailRepo.UpdateEmailStatus(email, database.EmailStatusSuccessNoData); err != nil {
+				bp.autoCrawler.LogLine(fmt.Sprintf("⚠️ Lỗi update database: %v", err))
+			}
+
+			bp.autoCrawler.LogLine(fmt.Sprintf("📭 Email không có thông tin LinkedIn: %s", email))
+			atomic.AddInt32(&crawlerInstance.Stats.Success, 1)
+			bp.recordDone(email, database.EmailStatusSuccessNoData)
+			bp.sinks.Write(email, models.ProfileData{}, database.EmailStatusSuccessNoData)
+		}
+
+		return true, true, &attemptLink
+	}
+
+	// If not last attempt and not successful, wait before retry
+	if attempt < maxRetries {
+		// Random delay between 200-600ms
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		delayMs := 200 + r.Intn(401)
+		delay := time.Duration(delayMs) * time.Millisecond
+
+		bp.autoCrawler.LogLine(fmt.Sprintf("⏳ Chờ %dms trước khi retry lần %d cho email: %s", delayMs, attempt+1, email))
+		time.Sleep(delay)
+	}
+
+	return false, false, &attemptLink
+}
@@ -0,0 +1,89 @@
+// Package coordinator abstracts "who owns which email/account/token right
+// now" behind a small interface, so BatchProcessor can run as a single
+// process (the default, backed by this run's own SQLite database) or as
+// one of several cooperating nodes sharing a queue (selected with
+// --coordinator=<url>).
+//
+// Only the local backend is implemented here: a Redis-backed coordinator
+// (or a Postgres SKIP LOCKED one) is the natural next step for true
+// multi-process fan-out, but no Redis client is vendored in this build
+// and there's no network access to add one, so New returns a clear error
+// for any non-local URL instead of faking the wire protocol - see
+// redis.go.
+package coordinator
+
+import (
+	"context"
+	"time"
+
+	"linkedin-crawler/internal/models"
+)
+
+// LeaseID identifies one batch of work leased out by LeaseEmails or
+// LeaseAccounts, passed back to AckEmails to release it.
+type LeaseID string
+
+// EmailResult is the outcome of processing one leased email, acknowledged
+// back to the coordinator via AckEmails so it can release the lease and
+// (for backends that support it) fan the result out to other nodes.
+type EmailResult struct {
+	Email   string
+	Status  string
+	Profile models.ProfileData
+}
+
+// TokenMeta describes a token published to the shared pool via
+// PublishToken, so other workers know where it came from and when.
+type TokenMeta struct {
+	WorkerID    string
+	PublishedAt time.Time
+}
+
+// WorkerStatus is a point-in-time view of one worker's throughput, as
+// reported through Heartbeat and surfaced by Status (and the `cluster
+// status` CLI command).
+type WorkerStatus struct {
+	WorkerID      string
+	LastHeartbeat time.Time
+	Processed     int64
+	Success       int64
+	Failed        int64
+}
+
+// ClusterStatus is a snapshot of every worker the coordinator currently
+// knows about.
+type ClusterStatus struct {
+	Workers []WorkerStatus
+}
+
+// Coordinator hands out leased work to one worker at a time and pools
+// results/tokens across workers. Implementations must be safe for
+// concurrent use - BatchProcessor calls it from its own goroutine, but a
+// distributed backend will have other processes calling it too.
+type Coordinator interface {
+	// LeaseEmails hands out up to n pending emails for ttl, after which an
+	// un-acknowledged lease expires and the emails become leasable again.
+	LeaseEmails(ctx context.Context, n int, ttl time.Duration) ([]string, LeaseID, error)
+
+	// AckEmails records results for a prior LeaseEmails call and releases
+	// its lease.
+	AckEmails(ctx context.Context, lease LeaseID, results []EmailResult) error
+
+	// LeaseAccounts hands out up to n unused accounts for ttl.
+	LeaseAccounts(ctx context.Context, n int, ttl time.Duration) ([]models.Account, LeaseID, error)
+
+	// PublishToken adds a token obtained by one worker to the shared pool
+	// so idle workers can borrow it.
+	PublishToken(ctx context.Context, token string, meta TokenMeta) error
+
+	// Heartbeat reports a worker's current throughput, keeping it visible
+	// in Status until the caller stops calling Heartbeat for it.
+	Heartbeat(ctx context.Context, stats WorkerStatus) error
+
+	// Status returns every worker this coordinator currently knows about.
+	Status(ctx context.Context) (ClusterStatus, error)
+
+	// Close releases any resources (connections, background goroutines)
+	// the coordinator holds.
+	Close() error
+}
@@ -0,0 +1,118 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/models"
+)
+
+// localCoordinator is the default, single-process backend: email/account
+// leasing is exactly the attemptID-based SQLite leasing EmailRepository
+// and AccountRepository already do for crash recovery, and the token
+// pool/worker registry are just in-process maps. Running several
+// processes against the same SQLite file would technically share leases
+// (SQLite serializes writers), but there's no heartbeat propagation across
+// processes - that's what the redis backend is for.
+type localCoordinator struct {
+	emailRepo   *database.EmailRepository
+	accountRepo *database.AccountRepository
+
+	mu      sync.Mutex
+	tokens  []publishedToken
+	workers map[string]WorkerStatus
+}
+
+type publishedToken struct {
+	token string
+	meta  TokenMeta
+}
+
+// newLocalCoordinator builds the default in-process coordinator.
+func newLocalCoordinator(emailRepo *database.EmailRepository, accountRepo *database.AccountRepository) *localCoordinator {
+	return &localCoordinator{
+		emailRepo:   emailRepo,
+		accountRepo: accountRepo,
+		workers:     make(map[string]WorkerStatus),
+	}
+}
+
+func (c *localCoordinator) LeaseEmails(_ context.Context, n int, ttl time.Duration) ([]string, LeaseID, error) {
+	lease := LeaseID(uuid.New().String())
+	emails, err := c.emailRepo.LeaseEmails(string(lease), n, ttl)
+	if err != nil {
+		return nil, "", fmt.Errorf("coordinator: failed to lease emails: %w", err)
+	}
+	return emails, lease, nil
+}
+
+func (c *localCoordinator) AckEmails(_ context.Context, lease LeaseID, results []EmailResult) error {
+	var firstErr error
+	for _, r := range results {
+		var err error
+		if r.Status == string(database.EmailStatusSuccessWithData) {
+			err = c.emailRepo.UpdateEmailWithProfile(r.Email, r.Profile)
+		} else {
+			err = c.emailRepo.UpdateEmailStatus(r.Email, database.EmailStatus(r.Status))
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("coordinator: failed to ack %s: %w", r.Email, err)
+		}
+	}
+	// UpdateEmailStatus/UpdateEmailWithProfile already clear the lease
+	// columns for each email, so there's no separate per-lease release
+	// step the way there is for emails that are abandoned without an Ack
+	// (those expire via ReclaimExpiredLeases instead).
+	return firstErr
+}
+
+func (c *localCoordinator) LeaseAccounts(ctx context.Context, n int, ttl time.Duration) ([]models.Account, LeaseID, error) {
+	// AccountRepository has no TTL-based lease like EmailRepository does -
+	// an account is marked used once and stays that way, so this is really
+	// a permanent lease rather than one that expires after ttl. Good
+	// enough for the single default worker; a backend that needs accounts
+	// to really come back after a dead worker would need that column
+	// added to AccountRepository first.
+	accounts, err := c.accountRepo.GetUnusedAccounts(ctx, n)
+	if err != nil {
+		return nil, "", fmt.Errorf("coordinator: failed to lease accounts: %w", err)
+	}
+	for _, acc := range accounts {
+		if err := c.accountRepo.MarkAccountAsUsed(ctx, acc.Email); err != nil {
+			return nil, "", fmt.Errorf("coordinator: failed to mark account used: %w", err)
+		}
+	}
+	return accounts, LeaseID(uuid.New().String()), nil
+}
+
+func (c *localCoordinator) PublishToken(_ context.Context, token string, meta TokenMeta) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens = append(c.tokens, publishedToken{token: token, meta: meta})
+	return nil
+}
+
+func (c *localCoordinator) Heartbeat(_ context.Context, stats WorkerStatus) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats.LastHeartbeat = time.Now()
+	c.workers[stats.WorkerID] = stats
+	return nil
+}
+
+func (c *localCoordinator) Status(_ context.Context) (ClusterStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := ClusterStatus{Workers: make([]WorkerStatus, 0, len(c.workers))}
+	for _, w := range c.workers {
+		status.Workers = append(status.Workers, w)
+	}
+	return status, nil
+}
+
+func (c *localCoordinator) Close() error { return nil }
@@ -0,0 +1,22 @@
+package coordinator
+
+import (
+	"fmt"
+	"strings"
+
+	"linkedin-crawler/internal/database"
+)
+
+// New builds a Coordinator from spec, the --coordinator flag's value.
+// Empty or "local" selects the default single-process backend; a
+// "redis://" URL selects the (currently unimplemented, see redis.go)
+// distributed backend.
+func New(spec string, emailRepo *database.EmailRepository, accountRepo *database.AccountRepository) (Coordinator, error) {
+	if spec == "" || spec == "local" {
+		return newLocalCoordinator(emailRepo, accountRepo), nil
+	}
+	if strings.HasPrefix(spec, "redis://") || strings.HasPrefix(spec, "rediss://") {
+		return newRedisCoordinator(spec)
+	}
+	return nil, fmt.Errorf("coordinator: unrecognized --coordinator value %q (expected \"local\" or a redis:// URL)", spec)
+}
@@ -0,0 +1,13 @@
+package coordinator
+
+import "fmt"
+
+// newRedisCoordinator backs --coordinator=redis://... URLs. No Redis
+// client is vendored in this build and there's no network access to add
+// one, so rather than fake the queue semantics over a raw TCP connection,
+// this fails fast with a clear error naming what's missing - the same
+// honest-substitute approach used for the Parquet/Kafka output sinks (see
+// internal/output/unsupported.go).
+func newRedisCoordinator(rawURL string) (Coordinator, error) {
+	return nil, fmt.Errorf("coordinator: redis backend (%s) requires a Redis client, which isn't vendored in this build - use --coordinator=local (the default) for single-process mode", rawURL)
+}
@@ -5,22 +5,43 @@ import (
 	"time"
 
 	"linkedin-crawler/internal/crawler"
+	"linkedin-crawler/internal/logging"
+	"linkedin-crawler/internal/notifier"
+	"linkedin-crawler/internal/tracing"
 )
 
 // RetryHandler handles retry logic for failed emails
 type RetryHandler struct {
 	autoCrawler *AutoCrawler
+	notifier    notifier.Notifier
 }
 
 // NewRetryHandler creates a new RetryHandler instance
-func NewRetryHandler(ac *AutoCrawler) *RetryHandler {
+func NewRetryHandler(ac *AutoCrawler) (*RetryHandler, error) {
+	n, err := notifier.New(ac.GetConfig().Notifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure notifier: %w", err)
+	}
+
 	return &RetryHandler{
 		autoCrawler: ac,
-	}
+		notifier:    n,
+	}, nil
 }
 
 // RetryFailedEmails handles Phase 2 retry - processes ALL remaining emails from file
-func (rh *RetryHandler) RetryFailedEmails() error {
+func (rh *RetryHandler) RetryFailedEmails() (err error) {
+	if !rh.autoCrawler.elector.IsLeader() {
+		logging.Logger.Info("⏸️ Không phải leader, bỏ qua Phase 2 retry")
+		return nil
+	}
+
+	ctx, rootSpan := tracing.StartSpan(rh.autoCrawler.shutdownCtx, "RetryFailedEmails")
+	defer func() {
+		rootSpan.SetStatus(err)
+		rootSpan.End()
+	}()
+
 	maxRetry := 7
 
 	for i := 1; i <= maxRetry; i++ {
@@ -30,27 +51,35 @@ func (rh *RetryHandler) RetryFailedEmails() error {
 		// Read remaining emails from file (includes both unprocessed and failed)
 		retryEmails, err := emailStorage.LoadEmailsFromFile(config.EmailsFilePath)
 		if err != nil || len(retryEmails) == 0 {
-			fmt.Println("✅ Không còn emails nào cần retry")
+			logging.Logger.Info("✅ Không còn emails nào cần retry")
 			return nil
 		}
 
-		fmt.Printf("🔄 Phase 2 - Lần %d: Retry %d emails còn lại...\n", i, len(retryEmails))
-		fmt.Println("⏳ Chờ 10 giây trước khi retry...")
-		time.Sleep(10 * time.Second)
+		roundCtx := logging.WithFields(ctx, "retry_attempt", i, "batch_size", len(retryEmails))
+		roundLog := logging.FromContext(roundCtx)
+
+		roundLog.Info("🔄 Phase 2 retry")
+		roundLog.Info("⏳ Chờ 10 giây trước khi retry...")
+		select {
+		case <-ctx.Done():
+			roundLog.Info("⚠️ Dừng retry do nhận tín hiệu shutdown")
+			return ctx.Err()
+		case <-time.After(10 * time.Second):
+		}
 
 		// Get tokens for retry
 		existingTokens, err := tokenStorage.LoadTokensFromFile(config.TokensFilePath)
 		if err != nil || len(existingTokens) == 0 {
-			fmt.Println("🔑 Không có tokens, lấy tokens mới cho retry...")
+			roundLog.Info("🔑 Không có tokens, lấy tokens mới cho retry...")
 			if rh.autoCrawler.GetUsedAccountIndex() < len(rh.autoCrawler.GetAccounts()) {
 				batchProcessor := rh.autoCrawler.batchProcessor
-				tokens, err := batchProcessor.getTokensBatch()
+				tokens, err := batchProcessor.getTokensBatch(ctx)
 				if err != nil {
 					return fmt.Errorf("không thể lấy tokens cho retry: %w", err)
 				}
 				existingTokens = tokens
 			} else {
-				fmt.Println("⚠️ Không còn accounts để lấy tokens cho retry")
+				roundLog.Warn("⚠️ Không còn accounts để lấy tokens cho retry")
 				return nil
 			}
 		}
@@ -62,11 +91,11 @@ func (rh *RetryHandler) RetryFailedEmails() error {
 		}
 
 		if len(validTokens) == 0 {
-			fmt.Println("❌ Không có tokens hợp lệ cho retry")
+			roundLog.Warn("❌ Không có tokens hợp lệ cho retry")
 			return nil
 		}
 
-		fmt.Printf("🔄 Retry với %d tokens hợp lệ...\n", len(validTokens))
+		roundLog.Info("🔄 Retry với tokens hợp lệ", "valid_tokens", len(validTokens))
 
 		// Initialize crawler for retry
 		if err := batchProcessor.initializeCrawler(validTokens); err != nil {
@@ -75,7 +104,7 @@ func (rh *RetryHandler) RetryFailedEmails() error {
 
 		// Record email count before retry
 		emailsBefore := len(retryEmails)
-		_, _ = batchProcessor.crawlWithCurrentTokens(retryEmails)
+		_, _ = batchProcessor.crawlWithCurrentTokens(roundCtx, retryEmails)
 
 		// Close crawler
 		crawlerInstance := rh.autoCrawler.GetCrawler()
@@ -89,16 +118,22 @@ func (rh *RetryHandler) RetryFailedEmails() error {
 		emailsAfter := len(emailsAfterList)
 
 		if emailsAfter == 0 {
-			fmt.Println("✅ Đã retry hết, không còn email nào cần retry nữa.")
+			roundLog.Info("✅ Đã retry hết, không còn email nào cần retry nữa")
+			if err := rh.notifier.Send(ctx, "retry batch complete", emailsBefore); err != nil {
+				roundLog.Warn("⚠️ Không thể gửi thông báo", "error", err)
+			}
 			break
 		}
 
 		if emailsAfter >= emailsBefore {
-			fmt.Println("⚠️ Không còn tiến triển trong retry, dừng")
+			roundLog.Warn("⚠️ Không còn tiến triển trong retry, dừng")
+			if err := rh.notifier.Send(ctx, "retry loop stalled", map[string]int{"before": emailsBefore, "after": emailsAfter}); err != nil {
+				roundLog.Warn("⚠️ Không thể gửi thông báo", "error", err)
+			}
 			break
 		}
 
-		fmt.Printf("📊 Retry lần %d: %d -> %d emails còn lại\n", i, emailsBefore, emailsAfter)
+		roundLog.Info("📊 Kết quả retry", "before", emailsBefore, "after", emailsAfter)
 	}
 	return nil
 }
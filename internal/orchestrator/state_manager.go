@@ -1,19 +1,30 @@
 package orchestrator
 
 import (
+	"context"
 	"fmt"
+
+	"linkedin-crawler/internal/logging"
+	"linkedin-crawler/internal/notifier"
 )
 
 // StateManager handles state persistence and management
 type StateManager struct {
 	autoCrawler *AutoCrawler
+	notifier    notifier.Notifier
 }
 
 // NewStateManager creates a new StateManager instance
-func NewStateManager(ac *AutoCrawler) *StateManager {
+func NewStateManager(ac *AutoCrawler) (*StateManager, error) {
+	n, err := notifier.New(ac.GetConfig().Notifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure notifier: %w", err)
+	}
+
 	return &StateManager{
 		autoCrawler: ac,
-	}
+		notifier:    n,
+	}, nil
 }
 
 func (sm *StateManager) HasEmailsToProcess() bool {
@@ -43,14 +54,38 @@ func (sm *StateManager) GetRemainingEmails() []string {
 	return emails
 }
 
-// SaveStateOnShutdown saves the current state when shutting down
+// SaveStateOnShutdown saves the current state when shutting down. In a
+// fleet with leader election, only the leader does this. A follower returns
+// at once: it neither releases its email leases or shard claim here nor
+// rewrites the shared emails file.
 func (sm *StateManager) SaveStateOnShutdown() {
+	if !sm.autoCrawler.elector.IsLeader() {
+		logging.Logger.Info("⏸️ Không phải leader, bỏ qua lưu trạng thái khi shutdown")
+		return
+	}
+
+	// Release any emails this run still has leased so the next run (or a
+	// cooperating worker) can pick them up immediately instead of waiting
+	// for the lease to expire
+	dbStorage := sm.autoCrawler.GetDBStorage()
+	if released, err := dbStorage.EmailRepo.ReleaseAttemptLeases(sm.autoCrawler.GetAttemptID()); err != nil {
+		logging.Logger.Error("⚠️ Không thể giải phóng email đang lease", "error", err)
+	} else if released > 0 {
+		logging.Logger.Info("💾 Đã trả lại emails đang xử lý dở về trạng thái pending", "count", released)
+	}
+
 	withData, withoutData, failed, permanent := sm.autoCrawler.GetEmailMaps()
 	totalEmails := sm.autoCrawler.GetTotalEmails()
 	emailStorage, _, _ := sm.autoCrawler.GetStorageServices()
 	config := sm.autoCrawler.GetConfig()
 	fileOpMutex := sm.autoCrawler.GetFileOpMutex()
 
+	if len(permanent) > 0 {
+		if err := sm.notifier.Send(context.Background(), "permanent failures on shutdown", len(permanent)); err != nil {
+			logging.Logger.Warn("⚠️ Không thể gửi thông báo", "error", err)
+		}
+	}
+
 	// Calculate remaining emails
 	var remainingEmails []string
 	for _, email := range totalEmails {
@@ -79,13 +114,13 @@ func (sm *StateManager) SaveStateOnShutdown() {
 	}
 
 	if len(remainingEmails) == 0 {
-		fmt.Println("📝 Tất cả emails đã được xử lý")
+		logging.Logger.Info("📝 Tất cả emails đã được xử lý")
 		// Create empty file with thread-safe operation
 		fileOpMutex.Lock()
 		err := emailStorage.WriteEmailsToFile(config.EmailsFilePath, []string{})
 		fileOpMutex.Unlock()
 		if err != nil {
-			fmt.Printf("⚠️ Không thể tạo file trống: %v\n", err)
+			logging.Logger.Error("⚠️ Không thể tạo file trống", "error", err)
 		}
 		return
 	}
@@ -95,13 +130,13 @@ func (sm *StateManager) SaveStateOnShutdown() {
 	err := emailStorage.WriteEmailsToFile(config.EmailsFilePath, remainingEmails)
 	fileOpMutex.Unlock()
 	if err != nil {
-		fmt.Printf("⚠️ Không thể ghi emails file khi shutdown: %v\n", err)
+		logging.Logger.Error("⚠️ Không thể ghi emails file khi shutdown", "error", err)
 		return
 	}
 
-	fmt.Printf("💾 Đã lưu %d emails chưa xử lý (Với data: %d, Không data: %d, Failed: %d, Permanent Failed: %d)\n",
-		len(remainingEmails), len(withData), len(withoutData),
-		len(failed), len(permanent))
+	logging.Logger.Info("💾 Đã lưu emails chưa xử lý",
+		"remaining", len(remainingEmails), "with_data", len(withData), "without_data", len(withoutData),
+		"failed", len(failed), "permanent_failed", len(permanent))
 }
 
 // UpdateEmailsFile updates the emails file with current state
@@ -144,8 +179,8 @@ func (sm *StateManager) UpdateEmailsFile() {
 	err := emailStorage.WriteEmailsToFile(config.EmailsFilePath, remainingEmails)
 	fileOpMutex.Unlock()
 	if err != nil {
-		fmt.Printf("⚠️ Không thể cập nhật emails file: %v\n", err)
+		logging.Logger.Error("⚠️ Không thể cập nhật emails file", "error", err)
 	} else {
-		fmt.Printf("💾 Đã cập nhật file emails: %d emails còn lại\n", len(remainingEmails))
+		logging.Logger.Info("💾 Đã cập nhật file emails", "remaining", len(remainingEmails))
 	}
 }
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/models"
+)
+
+// csvHeader lists the columns csvSink writes, in order. Unlike the text
+// sink's fixed pipe format, encoding/csv handles quoting any field that
+// contains the delimiter, a quote, or a newline.
+var csvHeader = []string{"email", "status", "user", "linkedin_url", "location", "connection_count"}
+
+// csvSink appends one row per crawl result, regardless of status - like
+// ndjson, it's meant as a complete audit trail rather than the
+// success-only hit.txt file.
+type csvSink struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *csv.Writer
+}
+
+func newCSVSink(path string) (*csvSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("csv sink: path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("csv sink: failed to create output directory: %w", err)
+	}
+
+	writeHeader := true
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		writeHeader = false
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("csv sink: failed to open %s: %w", path, err)
+	}
+
+	s := &csvSink{file: f, writer: csv.NewWriter(f)}
+	if writeHeader {
+		if err := s.writer.Write(csvHeader); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("csv sink: failed to write header: %w", err)
+		}
+		s.writer.Flush()
+	}
+	return s, nil
+}
+
+func (s *csvSink) Write(_ context.Context, email string, profile models.ProfileData, status database.EmailStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writer.Write([]string{
+		email, string(status), profile.User, profile.LinkedInURL, profile.Location, profile.ConnectionCount,
+	}); err != nil {
+		return fmt.Errorf("csv sink: failed to write record: %w", err)
+	}
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+func (s *csvSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+func (s *csvSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writer.Flush()
+	if err := s.writer.Error(); err != nil {
+		return err
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/models"
+)
+
+// ndjsonRecord is one line of an ndjsonSink's output file.
+type ndjsonRecord struct {
+	Email   string             `json:"email"`
+	Profile models.ProfileData `json:"profile"`
+	Status  string             `json:"status"`
+	Ts      time.Time          `json:"ts"`
+}
+
+// ndjsonSink appends one JSON object per line for every crawl result,
+// regardless of status - unlike the text sink, it's meant to be a
+// complete record for downstream ingestion (Spark/DuckDB can both read
+// newline-delimited JSON directly).
+type ndjsonSink struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+}
+
+func newNDJSONSink(path string) (*ndjsonSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("ndjson sink: path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("ndjson sink: failed to create output directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("ndjson sink: failed to open %s: %w", path, err)
+	}
+
+	return &ndjsonSink{file: f, writer: bufio.NewWriter(f)}, nil
+}
+
+func (s *ndjsonSink) Write(_ context.Context, email string, profile models.ProfileData, status database.EmailStatus) error {
+	line, err := json.Marshal(ndjsonRecord{Email: email, Profile: profile, Status: string(status), Ts: time.Now()})
+	if err != nil {
+		return fmt.Errorf("ndjson sink: failed to marshal record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.writer.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("ndjson sink: failed to write record: %w", err)
+	}
+	return s.writer.Flush()
+}
+
+func (s *ndjsonSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writer.Flush()
+}
+
+func (s *ndjsonSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+	return s.file.Close()
+}
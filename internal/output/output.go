@@ -0,0 +1,158 @@
+// Package output fans a crawl result out to a configurable set of
+// destinations instead of pinning it to a single hit.txt file: the
+// original text file, CSV, NDJSON, a user-supplied Go template, and an
+// HMAC-signed webhook are implemented; Parquet and Kafka/Redis Streams
+// sinks are recognized by models.OutputSpec but fail at construction (see
+// unsupported.go) since none of their client libraries are vendored in
+// this build and there's no network access to add them.
+//
+// Each configured Sink runs on its own bounded-queue worker goroutine, so
+// a slow or unreachable one (a stalled webhook endpoint, a full disk)
+// can't stall the others or the crawl itself - FanOut.Write only blocks
+// long enough to enqueue, dropping (with a logged warning) if that sink's
+// queue is still backed up from an earlier slowdown.
+package output
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/logging"
+	"linkedin-crawler/internal/models"
+)
+
+// Sink is one destination for crawl results. FanOut runs each Sink's Write
+// calls on a single worker goroutine, so Write never races another Write.
+// FanOut.Flush calls Flush from the caller's goroutine, so it can run
+// concurrently with that worker's Write; Close is called only after the
+// worker has drained and exited.
+type Sink interface {
+	Write(ctx context.Context, email string, profile models.ProfileData, status database.EmailStatus) error
+	Flush() error
+	Close() error
+}
+
+// queueSize bounds how many pending writes a sink's worker buffers before
+// FanOut starts dropping writes for that sink rather than blocking callers.
+const queueSize = 256
+
+type job struct {
+	email   string
+	profile models.ProfileData
+	status  database.EmailStatus
+}
+
+type worker struct {
+	name string
+	sink Sink
+	ch   chan job
+	wg   sync.WaitGroup
+}
+
+func newWorker(name string, sink Sink) *worker {
+	w := &worker{name: name, sink: sink, ch: make(chan job, queueSize)}
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+func (w *worker) run() {
+	defer w.wg.Done()
+	for j := range w.ch {
+		if err := w.sink.Write(context.Background(), j.email, j.profile, j.status); err != nil {
+			logging.Logger.Warn("⚠️ output sink write failed", "sink", w.name, "email", j.email, "error", err)
+		}
+	}
+}
+
+func (w *worker) enqueue(j job) {
+	select {
+	case w.ch <- j:
+	default:
+		logging.Logger.Warn("⚠️ output sink queue full, dropping write", "sink", w.name, "email", j.email)
+	}
+}
+
+// FanOut distributes Write calls to every configured Sink without letting
+// a slow one hold up the others or the caller.
+type FanOut struct {
+	workers []*worker
+}
+
+// New builds a FanOut from specs, in order. crawler returns the currently
+// active LinkedInCrawler, which the "text" sink appends to via its
+// existing hit.txt handle rather than opening its own (see textSink) - a
+// nil return just means no crawler instance is active right now (e.g.
+// between account rotations).
+func New(specs []models.OutputSpec, crawler func() *models.LinkedInCrawler) (*FanOut, error) {
+	f := &FanOut{}
+	for _, spec := range specs {
+		sink, err := build(spec, crawler)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("output: failed to configure %s sink: %w", spec.Type, err)
+		}
+		f.workers = append(f.workers, newWorker(spec.Type, sink))
+	}
+	return f, nil
+}
+
+func build(spec models.OutputSpec, crawler func() *models.LinkedInCrawler) (Sink, error) {
+	switch spec.Type {
+	case "text":
+		return newTextSink(crawler), nil
+	case "csv":
+		return newCSVSink(spec.Path)
+	case "ndjson":
+		return newNDJSONSink(spec.Path)
+	case "webhook":
+		return newWebhookSink(spec.URL, spec.Secret, spec.Path)
+	case "template":
+		return newTemplateSink(spec.Path, spec.Template)
+	case "parquet":
+		return newParquetSink(spec)
+	case "kafka", "redisstream":
+		return newStreamSink(spec)
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", spec.Type)
+	}
+}
+
+// Write enqueues email's result on every sink's worker. It never blocks on
+// a slow sink: a full queue drops the write for that sink only, logging a
+// warning, rather than stalling the crawl.
+func (f *FanOut) Write(email string, profile models.ProfileData, status database.EmailStatus) {
+	j := job{email: email, profile: profile, status: status}
+	for _, w := range f.workers {
+		w.enqueue(j)
+	}
+}
+
+// Flush flushes every sink, returning the first error encountered after
+// attempting all of them.
+func (f *FanOut) Flush() error {
+	var first error
+	for _, w := range f.workers {
+		if err := w.sink.Flush(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Close drains and stops every worker, then closes its sink. Returns the
+// first error encountered after attempting all of them.
+func (f *FanOut) Close() error {
+	var first error
+	for _, w := range f.workers {
+		close(w.ch)
+		w.wg.Wait()
+		if err := w.sink.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	f.workers = nil
+	return first
+}
@@ -0,0 +1,111 @@
+package output
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/models"
+)
+
+// templateFuncs are the handful of sprig string helpers users are most
+// likely to reach for in a one-line record template. sprig itself isn't
+// vendored in this build and there's no network access to add it, so this
+// is a small stdlib-only substitute rather than the real library - see
+// internal/output's package doc for the repo's general approach to
+// unvendored dependencies.
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"default": func(def, val string) string {
+		if val == "" {
+			return def
+		}
+		return val
+	},
+	"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+}
+
+// templateRecord is the value a templateSink's template is executed
+// against, mirroring ndjsonRecord's shape.
+type templateRecord struct {
+	Email   string
+	Profile models.ProfileData
+	Status  string
+	Ts      time.Time
+}
+
+// templateSink renders one user-supplied text/template per crawl result and
+// appends it to path, for integrations whose required format (a specific
+// TSV dialect, a log line shape a downstream ingester expects) isn't one of
+// the built-in sinks.
+type templateSink struct {
+	tmpl *template.Template
+
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+}
+
+func newTemplateSink(path, body string) (*templateSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("template sink: path is required")
+	}
+	if body == "" {
+		return nil, fmt.Errorf("template sink: template text is required")
+	}
+
+	tmpl, err := template.New("record").Funcs(templateFuncs).Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("template sink: failed to parse template: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("template sink: failed to create output directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("template sink: failed to open %s: %w", path, err)
+	}
+
+	return &templateSink{tmpl: tmpl, file: f, writer: bufio.NewWriter(f)}, nil
+}
+
+func (s *templateSink) Write(_ context.Context, email string, profile models.ProfileData, status database.EmailStatus) error {
+	rec := templateRecord{Email: email, Profile: profile, Status: string(status), Ts: time.Now()}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.tmpl.Execute(s.writer, rec); err != nil {
+		return fmt.Errorf("template sink: failed to render record: %w", err)
+	}
+	if _, err := s.writer.WriteString("\n"); err != nil {
+		return fmt.Errorf("template sink: failed to write record: %w", err)
+	}
+	return s.writer.Flush()
+}
+
+func (s *templateSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writer.Flush()
+}
+
+func (s *templateSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+	return s.file.Close()
+}
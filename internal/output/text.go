@@ -0,0 +1,41 @@
+package output
+
+import (
+	"context"
+	"fmt"
+
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/models"
+)
+
+// textSink is the original hit.txt output, kept as the default Sink so
+// existing deployments see no change in behavior. It only writes on
+// EmailStatusSuccessWithData, matching what BatchProcessor wrote before
+// FanOut existed - the other statuses are left to sinks that want a full
+// audit trail (ndjson, webhook).
+//
+// It delegates to the currently active LinkedInCrawler's own file handle
+// (crawler.New reopens hit.txt in append mode on every account rotation)
+// rather than managing a second handle on the same path.
+type textSink struct {
+	crawler func() *models.LinkedInCrawler
+}
+
+func newTextSink(crawler func() *models.LinkedInCrawler) *textSink {
+	return &textSink{crawler: crawler}
+}
+
+func (s *textSink) Write(_ context.Context, email string, profile models.ProfileData, status database.EmailStatus) error {
+	if status != database.EmailStatusSuccessWithData {
+		return nil
+	}
+
+	lc := s.crawler()
+	if lc == nil {
+		return fmt.Errorf("text sink: no active crawler instance")
+	}
+	return lc.WriteToFile(email, profile)
+}
+
+func (s *textSink) Flush() error { return nil }
+func (s *textSink) Close() error { return nil }
@@ -0,0 +1,147 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/models"
+)
+
+const (
+	webhookAttempts       = 4
+	webhookBackoffBase    = 500 * time.Millisecond
+	webhookBackoffCap     = 10 * time.Second
+	webhookRequestTimeout = 10 * time.Second
+)
+
+// webhookPayload is the JSON body POSTed to a webhook sink's URL.
+type webhookPayload struct {
+	Email   string             `json:"email"`
+	Profile models.ProfileData `json:"profile"`
+	Status  string             `json:"status"`
+	Ts      time.Time          `json:"ts"`
+}
+
+// webhookSink POSTs every result to a configured URL, HMAC-SHA256 signing
+// the body into an X-Signature-256 header so the receiver can verify it
+// came from this crawler. 5xx responses (and connection failures) are
+// retried with exponential backoff; if every attempt still fails, the
+// payload is appended to a spillover file instead of being dropped.
+//
+// The spillover file is append-only - replaying it into the endpoint once
+// it's back up is left to an operator/cron job reading the file, not
+// handled automatically by this sink.
+type webhookSink struct {
+	url    string
+	secret string
+	client *http.Client
+
+	mu    sync.Mutex
+	spill *os.File
+}
+
+func newWebhookSink(url, secret, spillPath string) (*webhookSink, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook sink: url is required")
+	}
+	if spillPath == "" {
+		spillPath = "webhook_spill.ndjson"
+	}
+
+	f, err := os.OpenFile(spillPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("webhook sink: failed to open spillover file %s: %w", spillPath, err)
+	}
+
+	return &webhookSink{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: webhookRequestTimeout},
+		spill:  f,
+	}, nil
+}
+
+func (s *webhookSink) Write(ctx context.Context, email string, profile models.ProfileData, status database.EmailStatus) error {
+	body, err := json.Marshal(webhookPayload{Email: email, Profile: profile, Status: string(status), Ts: time.Now()})
+	if err != nil {
+		return fmt.Errorf("webhook sink: failed to marshal payload: %w", err)
+	}
+
+	if err := s.postWithRetry(ctx, body); err != nil {
+		return s.spillToDisk(body, err)
+	}
+	return nil
+}
+
+func (s *webhookSink) postWithRetry(ctx context.Context, body []byte) error {
+	backoff := webhookBackoffBase
+	var lastErr error
+
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("webhook sink: failed to build request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Signature-256", s.sign(body))
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			lastErr = err
+		} else {
+			resp.Body.Close()
+			if resp.StatusCode < 500 {
+				if resp.StatusCode >= 300 {
+					return fmt.Errorf("webhook sink: %s returned %d", s.url, resp.StatusCode)
+				}
+				return nil
+			}
+			lastErr = fmt.Errorf("webhook sink: %s returned %d", s.url, resp.StatusCode)
+		}
+
+		if attempt < webhookAttempts {
+			time.Sleep(backoff + time.Duration(rand.Int63n(int64(backoff)/2+1)))
+			backoff *= 2
+			if backoff > webhookBackoffCap {
+				backoff = webhookBackoffCap
+			}
+		}
+	}
+
+	return lastErr
+}
+
+func (s *webhookSink) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *webhookSink) spillToDisk(body []byte, causeErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.spill.Write(append(body, '\n')); err != nil {
+		return fmt.Errorf("webhook sink: endpoint unreachable (%v) and spill failed: %w", causeErr, err)
+	}
+	return s.spill.Sync()
+}
+
+func (s *webhookSink) Flush() error { return nil }
+
+func (s *webhookSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spill.Close()
+}
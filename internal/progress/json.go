@@ -0,0 +1,92 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// jsonReporter emits one JSON object per line on every Update, for
+// ingestion by external monitors that don't want to scrape or parse a
+// terminal bar.
+type jsonReporter struct {
+	out *os.File
+
+	mu       sync.Mutex
+	finished bool
+
+	batchTotal int32
+}
+
+type jsonEvent struct {
+	Timestamp   string      `json:"ts"`
+	Processed   int32       `json:"processed"`
+	Success     int32       `json:"success"`
+	Failed      int32       `json:"failed"`
+	Active      int32       `json:"active"`
+	ValidTokens int         `json:"valid_tokens"`
+	TotalTokens int         `json:"total_tokens"`
+	BatchPct    float64     `json:"batch_pct"`
+	GlobalPct   float64     `json:"global_pct"`
+	Tokens      []TokenStat `json:"tokens,omitempty"`
+}
+
+func newJSONReporter(out *os.File) *jsonReporter {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &jsonReporter{out: out}
+}
+
+func (j *jsonReporter) SetTotal(batchTotal int) {
+	atomic.StoreInt32(&j.batchTotal, int32(batchTotal))
+}
+
+func (j *jsonReporter) Increment() {}
+
+func (j *jsonReporter) Update(s Snapshot) {
+	batchTotal := int(atomic.LoadInt32(&j.batchTotal))
+
+	batchPct := 0.0
+	if batchTotal > 0 {
+		batchPct = float64(s.Processed) * 100 / float64(batchTotal)
+	}
+	globalPct := 0.0
+	if s.GlobalTotal > 0 {
+		globalPct = float64(s.GlobalDone) * 100 / float64(s.GlobalTotal)
+	}
+
+	event := jsonEvent{
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Processed:   s.Processed,
+		Success:     s.Success,
+		Failed:      s.Failed,
+		Active:      s.Active,
+		ValidTokens: s.ValidTokens,
+		TotalTokens: s.TotalTokens,
+		BatchPct:    batchPct,
+		GlobalPct:   globalPct,
+		Tokens:      s.Tokens,
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.finished {
+		return
+	}
+	fmt.Fprintln(j.out, string(line))
+}
+
+func (j *jsonReporter) Finish() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.finished = true
+}
@@ -0,0 +1,37 @@
+package progress
+
+import (
+	"sync/atomic"
+
+	"linkedin-crawler/internal/metrics"
+)
+
+// prometheusReporter writes progress onto the crawler_batch_* and
+// crawler_global_* gauges already exposed on /metrics by the metrics
+// package, and nothing else - a scraper, not a human, is expected to be
+// watching.
+type prometheusReporter struct {
+	batchTotal int32
+}
+
+func newPrometheusReporter() *prometheusReporter {
+	return &prometheusReporter{}
+}
+
+func (p *prometheusReporter) SetTotal(batchTotal int) {
+	atomic.StoreInt32(&p.batchTotal, int32(batchTotal))
+	metrics.BatchTotal.Set(float64(batchTotal))
+}
+
+func (p *prometheusReporter) Increment() {}
+
+func (p *prometheusReporter) Update(s Snapshot) {
+	metrics.BatchProcessed.Set(float64(s.Processed))
+	metrics.BatchSuccess.Set(float64(s.Success))
+	metrics.BatchFailed.Set(float64(s.Failed))
+	metrics.BatchActive.Set(float64(s.Active))
+	metrics.GlobalProcessed.Set(float64(s.GlobalDone))
+	metrics.GlobalTotal.Set(float64(s.GlobalTotal))
+}
+
+func (p *prometheusReporter) Finish() {}
@@ -0,0 +1,97 @@
+package progress
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// ttyReporter draws a two-line, self-overwriting progress display (one line
+// for the current batch, one for the run as a whole) using the same
+// cursor-reset escape sequences the old inline ticker used.
+type ttyReporter struct {
+	out *os.File
+
+	mu           sync.Mutex
+	lastDisplay  string
+	firstDisplay bool
+	finished     bool
+
+	batchTotal int32
+	processed  int32
+}
+
+func newTTYReporter(out *os.File) *ttyReporter {
+	if out == nil {
+		out = os.Stderr
+	}
+	return &ttyReporter{out: out, firstDisplay: true}
+}
+
+func (t *ttyReporter) SetTotal(batchTotal int) {
+	atomic.StoreInt32(&t.batchTotal, int32(batchTotal))
+}
+
+func (t *ttyReporter) Increment() {
+	atomic.AddInt32(&t.processed, 1)
+}
+
+func (t *ttyReporter) Update(s Snapshot) {
+	batchTotal := int(atomic.LoadInt32(&t.batchTotal))
+
+	batchPercent := 0.0
+	if batchTotal > 0 {
+		batchPercent = float64(s.Processed) * 100 / float64(batchTotal)
+	}
+	globalPercent := 0.0
+	if s.GlobalTotal > 0 {
+		globalPercent = float64(s.GlobalDone) * 100 / float64(s.GlobalTotal)
+	}
+
+	const barWidth = 25
+	completedWidth := int(float64(barWidth) * batchPercent / 100)
+	bar := "["
+	for i := 0; i < barWidth; i++ {
+		switch {
+		case i < completedWidth:
+			bar += "█"
+		case i == completedWidth && batchPercent > 0 && completedWidth < barWidth:
+			bar += "▓"
+		default:
+			bar += "░"
+		}
+	}
+	bar += "]"
+
+	line1 := fmt.Sprintf("🔄 Batch: %s %.1f%% (%d/%d) | Success: %d | Failed: %d | Active: %d | Tokens: %d/%d",
+		bar, batchPercent, s.Processed, batchTotal, s.Success, s.Failed, s.Active, s.ValidTokens, s.TotalTokens)
+	line2 := fmt.Sprintf("📊 Total: %.1f%% (%d/%d)", globalPercent, s.GlobalDone, s.GlobalTotal)
+
+	newDisplay := line1 + "\n" + line2
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished || newDisplay == t.lastDisplay {
+		return
+	}
+	if !t.firstDisplay {
+		fmt.Fprintf(t.out, "\r\033[A\033[K\033[K")
+	}
+	fmt.Fprintf(t.out, "%s\n%s", line1, line2)
+	t.lastDisplay = newDisplay
+	t.firstDisplay = false
+}
+
+func (t *ttyReporter) Finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished {
+		return
+	}
+	if !t.firstDisplay {
+		fmt.Fprintf(t.out, "\r\033[A\033[K\033[K\r")
+	}
+	fmt.Fprintln(t.out)
+	t.finished = true
+}
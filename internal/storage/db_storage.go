@@ -2,6 +2,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"strings"
@@ -17,22 +18,40 @@ type DBStorage struct {
 	EmailRepo   *database.EmailRepository
 	TokenRepo   *database.TokenRepository
 	AccountRepo *database.AccountRepository
+	ProxyRepo   *database.ProxyRepository
 	mutex       sync.Mutex
 }
 
-// NewDBStorage creates a new database storage
+// NewDBStorage creates a new database storage, applying pending migrations
+// but keeping any previously crawled data intact
 func NewDBStorage(dbPath string) (*DBStorage, error) {
 	db, err := database.New(dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create database: %w", err)
 	}
 
+	return newDBStorageFromDB(db), nil
+}
+
+// NewDBStorageWithReset is like NewDBStorage but wipes the schema back to
+// empty first, for the rare case a user actually wants to start over.
+func NewDBStorageWithReset(dbPath string) (*DBStorage, error) {
+	db, err := database.NewWithReset(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	}
+
+	return newDBStorageFromDB(db), nil
+}
+
+func newDBStorageFromDB(db *database.DB) *DBStorage {
 	return &DBStorage{
 		DB:          db,
 		EmailRepo:   database.NewEmailRepository(db),
 		TokenRepo:   database.NewTokenRepository(db),
 		AccountRepo: database.NewAccountRepository(db),
-	}, nil
+		ProxyRepo:   database.NewProxyRepository(db),
+	}
 }
 
 // Close closes the database connection
@@ -80,7 +99,9 @@ func (ds *DBStorage) ImportAccountsFromFile(filePath string) error {
 		return err
 	}
 
-	return ds.AccountRepo.ImportAccounts(accounts)
+	// One-shot startup import, run before the crawler's cancellable
+	// lifetime context exists, so there's nothing to plumb through yet.
+	return ds.AccountRepo.ImportAccounts(context.Background(), accounts)
 }
 
 // ImportTokensFromFile imports tokens from file into database
@@ -105,6 +126,27 @@ func (ds *DBStorage) ImportTokensFromFile(filePath string) error {
 	return ds.TokenRepo.AddTokens(tokens)
 }
 
+// ImportProxiesFromFile imports proxies from file into database
+func (ds *DBStorage) ImportProxiesFromFile(filePath string) error {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		// No existing proxies file is OK
+		return nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var proxies []string
+
+	for _, line := range lines {
+		proxyURL := strings.TrimSpace(line)
+		if proxyURL != "" && !strings.HasPrefix(proxyURL, "#") {
+			proxies = append(proxies, proxyURL)
+		}
+	}
+
+	return ds.ProxyRepo.AddProxies(proxies)
+}
+
 // Updated EmailStorage to use database
 type EmailStorage struct {
 	dbStorage *DBStorage
@@ -184,6 +226,45 @@ func (ts *TokenStorage) RemoveTokenFromFile(filePath string, tokenToRemove strin
 	return ts.dbStorage.TokenRepo.InvalidateToken(tokenToRemove)
 }
 
+// Updated ProxyStorage to use database
+type ProxyStorage struct {
+	dbStorage *DBStorage
+}
+
+// NewProxyStorage creates a new ProxyStorage that uses database
+func NewProxyStorage() *ProxyStorage {
+	return &ProxyStorage{}
+}
+
+// SetDBStorage sets the database storage
+func (ps *ProxyStorage) SetDBStorage(ds *DBStorage) {
+	ps.dbStorage = ds
+}
+
+// LoadProxiesFromFile returns valid proxies from database
+func (ps *ProxyStorage) LoadProxiesFromFile(filePath string) ([]string, error) {
+	if ps.dbStorage == nil {
+		return nil, fmt.Errorf("database storage not initialized")
+	}
+	return ps.dbStorage.ProxyRepo.GetValidProxies()
+}
+
+// SaveProxiesToFile adds proxies to database
+func (ps *ProxyStorage) SaveProxiesToFile(filePath string, proxies []string) error {
+	if ps.dbStorage == nil {
+		return fmt.Errorf("database storage not initialized")
+	}
+	return ps.dbStorage.ProxyRepo.AddProxies(proxies)
+}
+
+// RemoveProxyFromFile invalidates a proxy in database
+func (ps *ProxyStorage) RemoveProxyFromFile(filePath string, proxyToRemove string) error {
+	if ps.dbStorage == nil {
+		return fmt.Errorf("database storage not initialized")
+	}
+	return ps.dbStorage.ProxyRepo.InvalidateProxy(proxyToRemove)
+}
+
 // Updated AccountStorage to use database
 type AccountStorage struct {
 	dbStorage *DBStorage
@@ -200,20 +281,20 @@ func (as *AccountStorage) SetDBStorage(ds *DBStorage) {
 }
 
 // LoadAccounts returns unused accounts from database
-func (as *AccountStorage) LoadAccounts(filename string) ([]models.Account, error) {
+func (as *AccountStorage) LoadAccounts(ctx context.Context, filename string) ([]models.Account, error) {
 	if as.dbStorage == nil {
 		return nil, fmt.Errorf("database storage not initialized")
 	}
 	// Get all unused accounts
-	return as.dbStorage.AccountRepo.GetUnusedAccounts(0)
+	return as.dbStorage.AccountRepo.GetUnusedAccounts(ctx, 0)
 }
 
 // RemoveAccountFromFile marks account as used in database
-func (as *AccountStorage) RemoveAccountFromFile(filePath string, acc models.Account) error {
+func (as *AccountStorage) RemoveAccountFromFile(ctx context.Context, filePath string, acc models.Account) error {
 	if as.dbStorage == nil {
 		return fmt.Errorf("database storage not initialized")
 	}
-	return as.dbStorage.AccountRepo.MarkAccountAsUsed(acc.Email)
+	return as.dbStorage.AccountRepo.MarkAccountAsUsed(ctx, acc.Email)
 }
 
 // Legacy function wrappers - now use database
@@ -222,6 +303,7 @@ var (
 	globalEmailStorage   = NewEmailStorage()
 	globalTokenStorage   = NewTokenStorage()
 	globalAccountStorage = NewAccountStorage()
+	globalProxyStorage   = NewProxyStorage()
 )
 
 // InitializeDatabase initializes the global database storage
@@ -231,12 +313,28 @@ func InitializeDatabase(dbPath string) error {
 		return err
 	}
 
+	setGlobalDBStorage(ds)
+	return nil
+}
+
+// InitializeDatabaseWithReset is like InitializeDatabase but wipes the
+// schema back to empty first (the --reset CLI flag).
+func InitializeDatabaseWithReset(dbPath string) error {
+	ds, err := NewDBStorageWithReset(dbPath)
+	if err != nil {
+		return err
+	}
+
+	setGlobalDBStorage(ds)
+	return nil
+}
+
+func setGlobalDBStorage(ds *DBStorage) {
 	globalDBStorage = ds
 	globalEmailStorage.SetDBStorage(ds)
 	globalTokenStorage.SetDBStorage(ds)
 	globalAccountStorage.SetDBStorage(ds)
-
-	return nil
+	globalProxyStorage.SetDBStorage(ds)
 }
 
 // CloseDatabase closes the global database
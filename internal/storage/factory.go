@@ -0,0 +1,53 @@
+// Package storage abstracts email/token/account persistence behind the
+// Storage interface so the crawler isn't hard-wired to SQLite: "sqlite"
+// (the default, wrapping DBStorage) and "memory" (internal/storage/memory,
+// for tests) are implemented. "postgres", "mysql", "etcd", and
+// "s3+sqlite" are recognized by models.StorageConfig for the horizontal-
+// scale deployments they're meant to support, but fail at construction
+// (see NewStorage) since none of their client libraries are vendored in
+// this build and there's no network access to add them.
+package storage
+
+import (
+	"fmt"
+
+	"linkedin-crawler/internal/models"
+)
+
+// NewStorage builds a Storage backend from cfg.Type. "sqlite" (the default)
+// wraps the existing DBStorage; other drivers are routed here too so
+// callers only need to branch on config, not on concrete backend types.
+// Drivers that require an external dependency we don't vendor yet
+// (Postgres, MySQL, etcd, S3) report a clear error instead of silently
+// falling back, so a misconfiguration fails fast at startup. The in-memory
+// backend lives in internal/storage/memory to avoid an import cycle; wire
+// it up at the call site with memory.New() when cfg.Type == "memory".
+func NewStorage(cfg models.StorageConfig) (Storage, error) {
+	switch cfg.Type {
+	case "", "sqlite":
+		path := cfg.SQLitePath
+		if path == "" {
+			path = "crawler.db"
+		}
+		ds, err := NewDBStorage(path)
+		if err != nil {
+			return nil, err
+		}
+		return AsStorage(ds), nil
+
+	case "postgres", "mysql":
+		return nil, fmt.Errorf("storage: %s driver is not vendored in this build; configure Storage.Type=sqlite or build with the driver's database/sql package available", cfg.Type)
+
+	case "etcd":
+		return nil, fmt.Errorf("storage: etcd driver is not vendored in this build; configure Storage.Type=sqlite for single-node use")
+
+	case "memory":
+		return nil, fmt.Errorf("storage: memory backend must be constructed via internal/storage/memory.New() to avoid an import cycle")
+
+	case "s3+sqlite":
+		return nil, fmt.Errorf("storage: s3+sqlite requires an S3-compatible client library that isn't vendored in this build; configure Storage.Type=sqlite and archive hit.txt out-of-band (e.g. aws s3 cp) until one is available")
+
+	default:
+		return nil, fmt.Errorf("storage: unknown backend type %q", cfg.Type)
+	}
+}
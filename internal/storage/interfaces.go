@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/models"
+)
+
+// EmailStore abstracts persistence of email crawl state so the crawler is
+// not hard-wired to a specific SQL driver.
+type EmailStore interface {
+	ImportEmails(emails []string) error
+	GetPendingEmails(limit int) ([]string, error)
+	GetEmailsByStatus(status database.EmailStatus) ([]string, error)
+	UpdateEmailStatus(email string, status database.EmailStatus) error
+	UpdateEmailWithProfile(email string, profile models.ProfileData) error
+	IncrementRetryCount(email string, lastError string) error
+	GetEmailStats() (map[string]int, error)
+	GetRemainingEmails() ([]string, error)
+	CountRemainingEmails() (int, error)
+}
+
+// TokenStore abstracts persistence of LinkedIn API tokens.
+type TokenStore interface {
+	AddToken(token string) error
+	AddTokens(tokens []string) error
+	GetValidTokens() ([]string, error)
+	MarkTokenAsUsed(token string) error
+	InvalidateToken(token string) error
+	IncrementTokenFailure(token string) error
+	GetValidTokenCount() (int, error)
+}
+
+// AccountStore abstracts persistence of login accounts used to mint tokens.
+type AccountStore interface {
+	ImportAccounts(ctx context.Context, accounts []models.Account) error
+	GetUnusedAccounts(ctx context.Context, limit int) ([]models.Account, error)
+	MarkAccountAsUsed(ctx context.Context, email string) error
+	GetUnusedAccountCount(ctx context.Context) (int, error)
+}
+
+// Storage bundles the three repository interfaces a backend must provide.
+// DBStorage (SQLite, wrapped by dbStorageAdapter) and the in-memory test
+// backend both implement it, so AutoCrawler can depend on the interface
+// instead of a concrete driver.
+type Storage interface {
+	EmailStore() EmailStore
+	TokenStore() TokenStore
+	AccountStore() AccountStore
+	Close() error
+}
+
+// Compile-time assertion that the existing SQLite-backed DBStorage satisfies
+// Storage once wrapped by dbStorageAdapter.
+var _ Storage = (*dbStorageAdapter)(nil)
+
+// dbStorageAdapter adapts the concrete *database.DB repositories to the
+// Storage interface without changing their existing method signatures.
+type dbStorageAdapter struct {
+	ds *DBStorage
+}
+
+func (a *dbStorageAdapter) EmailStore() EmailStore     { return a.ds.EmailRepo }
+func (a *dbStorageAdapter) TokenStore() TokenStore     { return a.ds.TokenRepo }
+func (a *dbStorageAdapter) AccountStore() AccountStore { return a.ds.AccountRepo }
+func (a *dbStorageAdapter) Close() error               { return a.ds.Close() }
+
+// AsStorage wraps an existing DBStorage so it can be consumed through the
+// Storage interface, e.g. by backend factories in NewStorage.
+func AsStorage(ds *DBStorage) Storage {
+	return &dbStorageAdapter{ds: ds}
+}
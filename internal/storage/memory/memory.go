@@ -0,0 +1,255 @@
+// Package memory provides an in-memory implementation of storage.Storage
+// for unit tests and local experimentation, so exercising the crawler
+// against a backend doesn't require a SQLite file on disk.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/models"
+	"linkedin-crawler/internal/storage"
+)
+
+// Compile-time assertion that Backend satisfies storage.Storage.
+var _ storage.Storage = (*Backend)(nil)
+
+type emailRecord struct {
+	status     database.EmailStatus
+	profile    models.ProfileData
+	retryCount int
+	lastError  string
+}
+
+type tokenRecord struct {
+	isValid      bool
+	failureCount int
+}
+
+// Backend is an in-memory Storage implementation. It is safe for concurrent
+// use and keeps state only for the lifetime of the process.
+type Backend struct {
+	mu sync.Mutex
+
+	emails  map[string]*emailRecord
+	order   []string
+	tokens  map[string]*tokenRecord
+	accts   map[string]models.Account
+	acctsOK map[string]bool // email -> used
+}
+
+// New creates an empty in-memory Storage backend.
+func New() *Backend {
+	return &Backend{
+		emails:  make(map[string]*emailRecord),
+		tokens:  make(map[string]*tokenRecord),
+		accts:   make(map[string]models.Account),
+		acctsOK: make(map[string]bool),
+	}
+}
+
+func (b *Backend) EmailStore() storage.EmailStore { return b }
+
+func (b *Backend) TokenStore() storage.TokenStore { return b }
+
+func (b *Backend) AccountStore() storage.AccountStore { return b }
+
+// Close is a no-op for the in-memory backend.
+func (b *Backend) Close() error { return nil }
+
+// --- EmailStore ---
+
+func (b *Backend) ImportEmails(emails []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, email := range emails {
+		if email == "" {
+			continue
+		}
+		if _, ok := b.emails[email]; ok {
+			continue
+		}
+		b.emails[email] = &emailRecord{status: database.EmailStatusPending}
+		b.order = append(b.order, email)
+	}
+	return nil
+}
+
+func (b *Backend) GetPendingEmails(limit int) ([]string, error) {
+	return b.GetEmailsByStatus(database.EmailStatusPending)
+}
+
+func (b *Backend) GetEmailsByStatus(status database.EmailStatus) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []string
+	for _, email := range b.order {
+		if b.emails[email].status == status {
+			out = append(out, email)
+		}
+	}
+	return out, nil
+}
+
+func (b *Backend) UpdateEmailStatus(email string, status database.EmailStatus) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	rec, ok := b.emails[email]
+	if !ok {
+		return nil
+	}
+	rec.status = status
+	return nil
+}
+
+func (b *Backend) UpdateEmailWithProfile(email string, profile models.ProfileData) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	rec, ok := b.emails[email]
+	if !ok {
+		return nil
+	}
+	rec.status = database.EmailStatusSuccessWithData
+	rec.profile = profile
+	return nil
+}
+
+func (b *Backend) IncrementRetryCount(email string, lastError string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	rec, ok := b.emails[email]
+	if !ok {
+		return nil
+	}
+	rec.retryCount++
+	rec.lastError = lastError
+	return nil
+}
+
+func (b *Backend) GetEmailStats() (map[string]int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := make(map[string]int)
+	for _, rec := range b.emails {
+		stats[string(rec.status)]++
+	}
+	stats["total"] = len(b.emails)
+	return stats, nil
+}
+
+func (b *Backend) GetRemainingEmails() ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []string
+	for _, email := range b.order {
+		status := b.emails[email].status
+		if status == database.EmailStatusPending || status == database.EmailStatusFailed {
+			out = append(out, email)
+		}
+	}
+	return out, nil
+}
+
+func (b *Backend) CountRemainingEmails() (int, error) {
+	emails, err := b.GetRemainingEmails()
+	return len(emails), err
+}
+
+// --- TokenStore ---
+
+func (b *Backend) AddToken(token string) error {
+	return b.AddTokens([]string{token})
+}
+
+func (b *Backend) AddTokens(tokens []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, token := range tokens {
+		if _, ok := b.tokens[token]; ok {
+			continue
+		}
+		b.tokens[token] = &tokenRecord{isValid: true}
+	}
+	return nil
+}
+
+func (b *Backend) GetValidTokens() ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []string
+	for token, rec := range b.tokens {
+		if rec.isValid {
+			out = append(out, token)
+		}
+	}
+	return out, nil
+}
+
+func (b *Backend) MarkTokenAsUsed(token string) error {
+	return nil
+}
+
+func (b *Backend) InvalidateToken(token string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rec, ok := b.tokens[token]; ok {
+		rec.isValid = false
+	}
+	return nil
+}
+
+func (b *Backend) IncrementTokenFailure(token string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rec, ok := b.tokens[token]; ok {
+		rec.failureCount++
+	}
+	return nil
+}
+
+func (b *Backend) GetValidTokenCount() (int, error) {
+	tokens, _ := b.GetValidTokens()
+	return len(tokens), nil
+}
+
+// --- AccountStore ---
+
+func (b *Backend) ImportAccounts(ctx context.Context, accounts []models.Account) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, account := range accounts {
+		if _, ok := b.accts[account.Email]; ok {
+			continue
+		}
+		b.accts[account.Email] = account
+	}
+	return nil
+}
+
+func (b *Backend) GetUnusedAccounts(ctx context.Context, limit int) ([]models.Account, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []models.Account
+	for _, account := range b.accts {
+		if !b.acctsOK[account.Email] {
+			out = append(out, account)
+			if limit > 0 && len(out) >= limit {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (b *Backend) MarkAccountAsUsed(ctx context.Context, email string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.acctsOK[email] = true
+	return nil
+}
+
+func (b *Backend) GetUnusedAccountCount(ctx context.Context) (int, error) {
+	accounts, _ := b.GetUnusedAccounts(ctx, 0)
+	return len(accounts), nil
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"linkedin-crawler/internal/database"
+	"linkedin-crawler/internal/models"
+)
+
+func TestBackendEmailLifecycle(t *testing.T) {
+	b := New()
+
+	if err := b.ImportEmails([]string{"a@example.com", "b@example.com", "a@example.com"}); err != nil {
+		t.Fatalf("ImportEmails: %v", err)
+	}
+
+	pending, err := b.GetPendingEmails(0)
+	if err != nil {
+		t.Fatalf("GetPendingEmails: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending emails (duplicate import ignored), got %v", pending)
+	}
+
+	if err := b.UpdateEmailStatus("a@example.com", database.EmailStatusFailed); err != nil {
+		t.Fatalf("UpdateEmailStatus: %v", err)
+	}
+	if err := b.IncrementRetryCount("a@example.com", "boom"); err != nil {
+		t.Fatalf("IncrementRetryCount: %v", err)
+	}
+
+	remaining, err := b.GetRemainingEmails()
+	if err != nil {
+		t.Fatalf("GetRemainingEmails: %v", err)
+	}
+	if len(remaining) != 2 {
+		t.Fatalf("failed + pending should both count as remaining, got %v", remaining)
+	}
+
+	if err := b.UpdateEmailWithProfile("b@example.com", models.ProfileData{}); err != nil {
+		t.Fatalf("UpdateEmailWithProfile: %v", err)
+	}
+	n, err := b.CountRemainingEmails()
+	if err != nil {
+		t.Fatalf("CountRemainingEmails: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 remaining after b@example.com succeeded, got %d", n)
+	}
+}
+
+func TestBackendTokenLifecycle(t *testing.T) {
+	b := New()
+
+	if err := b.AddTokens([]string{"tok-1", "tok-2"}); err != nil {
+		t.Fatalf("AddTokens: %v", err)
+	}
+	if count, err := b.GetValidTokenCount(); err != nil || count != 2 {
+		t.Fatalf("expected 2 valid tokens, got %d, err %v", count, err)
+	}
+
+	if err := b.InvalidateToken("tok-1"); err != nil {
+		t.Fatalf("InvalidateToken: %v", err)
+	}
+	valid, err := b.GetValidTokens()
+	if err != nil {
+		t.Fatalf("GetValidTokens: %v", err)
+	}
+	if len(valid) != 1 || valid[0] != "tok-2" {
+		t.Fatalf("expected only tok-2 valid, got %v", valid)
+	}
+}
+
+func TestBackendAccountLifecycle(t *testing.T) {
+	b := New()
+	ctx := context.Background()
+
+	accounts := []models.Account{{Email: "u1@example.com"}, {Email: "u2@example.com"}}
+	if err := b.ImportAccounts(ctx, accounts); err != nil {
+		t.Fatalf("ImportAccounts: %v", err)
+	}
+
+	if count, err := b.GetUnusedAccountCount(ctx); err != nil || count != 2 {
+		t.Fatalf("expected 2 unused accounts, got %d, err %v", count, err)
+	}
+
+	unused, err := b.GetUnusedAccounts(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetUnusedAccounts: %v", err)
+	}
+	if len(unused) != 1 {
+		t.Fatalf("limit 1 should return exactly 1 account, got %v", unused)
+	}
+
+	if err := b.MarkAccountAsUsed(ctx, unused[0].Email); err != nil {
+		t.Fatalf("MarkAccountAsUsed: %v", err)
+	}
+	if count, err := b.GetUnusedAccountCount(ctx); err != nil || count != 1 {
+		t.Fatalf("expected 1 unused account after marking one used, got %d, err %v", count, err)
+	}
+}
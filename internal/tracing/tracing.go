@@ -0,0 +1,155 @@
+// Package tracing provides a minimal span abstraction for correlating a
+// single email's journey across token-acquisition batches and retry
+// attempts.
+//
+// go.opentelemetry.io isn't vendored in this module, and the sandbox this
+// repo is built in has no network access to add it, so this package does
+// not export real OTLP spans to a Tempo/Jaeger collector. Instead it logs
+// each finished span - trace_id/span_id/parent_span_id correlation fields,
+// any links, and its attributes - through internal/logging, which already
+// gives operators a structured, greppable log stream. If
+// OTEL_EXPORTER_OTLP_ENDPOINT is set, a one-time warning says so instead of
+// silently pretending traces are being exported there.
+package tracing
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"linkedin-crawler/internal/logging"
+)
+
+type ctxKey struct{}
+
+// Link references another span - e.g. a previous retry attempt for the same
+// email - the way an OTel span link relates causally-adjacent spans that
+// aren't in a direct parent/child relationship.
+type Link struct {
+	TraceID string
+	SpanID  string
+}
+
+// Span is a single traced operation. Create one with StartSpan and finish it
+// with End; attributes, links, and status may be set any time in between.
+type Span struct {
+	name     string
+	traceID  string
+	spanID   string
+	parentID string
+	start    time.Time
+
+	mu    sync.Mutex
+	attrs []any
+	links []Link
+	err   error
+}
+
+// StartSpan begins a new span named name, parented to whatever span is
+// already carried by ctx (if any), and returns a context carrying the new
+// span so children started from it parent correctly.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	checkOTLPEndpoint()
+
+	span := &Span{
+		name:   name,
+		spanID: newID(),
+		start:  time.Now(),
+	}
+
+	if parent, ok := ctx.Value(ctxKey{}).(*Span); ok && parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		span.traceID = newID()
+	}
+
+	return context.WithValue(ctx, ctxKey{}, span), span
+}
+
+func newID() string {
+	return strings.ReplaceAll(uuid.New().String(), "-", "")[:16]
+}
+
+// SetAttributes appends key/value pairs to report when the span ends, in
+// the same key1, val1, key2, val2, ... shape slog.Logger.Info accepts.
+func (s *Span) SetAttributes(kv ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, kv...)
+}
+
+// SetStatus records the span's outcome; a nil err marks it successful.
+func (s *Span) SetStatus(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// AddLink associates another span with this one.
+func (s *Span) AddLink(l Link) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links = append(s.links, l)
+}
+
+// TraceID returns the trace this span belongs to.
+func (s *Span) TraceID() string { return s.traceID }
+
+// SpanID returns this span's own id.
+func (s *Span) SpanID() string { return s.spanID }
+
+// Link returns a Link pointing at this span, for passing to a later span's
+// AddLink.
+func (s *Span) Link() Link {
+	return Link{TraceID: s.traceID, SpanID: s.spanID}
+}
+
+// End finishes the span and logs it through internal/logging.
+func (s *Span) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fields := make([]any, 0, len(s.attrs)+8)
+	fields = append(fields,
+		"trace_id", s.traceID,
+		"span_id", s.spanID,
+		"duration_ms", time.Since(s.start).Milliseconds(),
+	)
+	if s.parentID != "" {
+		fields = append(fields, "parent_span_id", s.parentID)
+	}
+	if len(s.links) > 0 {
+		linkIDs := make([]string, len(s.links))
+		for i, l := range s.links {
+			linkIDs[i] = l.SpanID
+		}
+		fields = append(fields, "link_span_ids", strings.Join(linkIDs, ","))
+	}
+	fields = append(fields, s.attrs...)
+
+	if s.err != nil {
+		fields = append(fields, "status", "error", "error", s.err)
+		logging.Logger.Error("span: "+s.name, fields...)
+		return
+	}
+	fields = append(fields, "status", "ok")
+	logging.Logger.Info("span: "+s.name, fields...)
+}
+
+var otlpWarnOnce sync.Once
+
+// checkOTLPEndpoint warns once, on the first span started, if the operator
+// has pointed OTEL_EXPORTER_OTLP_ENDPOINT at a collector - this build has no
+// OTLP exporter wired up, so that collector will never receive anything.
+func checkOTLPEndpoint() {
+	otlpWarnOnce.Do(func() {
+		if ep := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); ep != "" {
+			logging.Logger.Warn("⚠️ OTEL_EXPORTER_OTLP_ENDPOINT is set but this build has no OTLP exporter wired up; spans are logged locally instead", "endpoint", ep)
+		}
+	})
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"time"
@@ -13,6 +15,23 @@ func PrintErr(message string) {
 	}
 }
 
+// TokenFingerprint returns a short, non-reversible-looking prefix of a
+// bearer token suitable for log/metric labels, so tokens never end up in
+// logs or Prometheus label values in full.
+func TokenFingerprint(token string) string {
+	if len(token) <= 8 {
+		return token
+	}
+	return token[:8]
+}
+
+// EmailHash returns a short SHA-256 hash of email, suitable for trace/span
+// attributes and metric labels where the raw address shouldn't appear.
+func EmailHash(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
 // FormatDuration formats duration in a human-readable way
 func FormatDuration(d time.Duration) string {
 	if d < time.Minute {
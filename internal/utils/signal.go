@@ -1,22 +1,29 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
-	"sync/atomic"
 	"syscall"
 	"time"
 )
 
-// SetupSignalHandling sets up signal handling for graceful shutdown
-func SetupSignalHandling(shutdownRequested *int32, onShutdown func(), sleepDuration time.Duration) {
+// SetupSignalHandling installs a SIGINT/SIGTERM handler and returns a
+// context that's cancelled the instant one arrives, so callers selecting on
+// ctx.Done() (SQL calls, HTTP requests, retry sleeps) unblock immediately
+// instead of running to completion first. onShutdown runs synchronously
+// after cancellation to persist state, then the process sleeps
+// sleepDuration (to let anything still draining finish) before exiting.
+func SetupSignalHandling(onShutdown func(), sleepDuration time.Duration) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		sig := <-sigCh
 		fmt.Printf("\n⚠️ Nhận signal %v, đang shutdown...\n", sig)
-		atomic.StoreInt32(shutdownRequested, 1)
+		cancel()
 
 		if onShutdown != nil {
 			onShutdown()
@@ -26,4 +33,6 @@ func SetupSignalHandling(shutdownRequested *int32, onShutdown func(), sleepDurat
 		time.Sleep(sleepDuration)
 		os.Exit(0)
 	}()
+
+	return ctx
 }